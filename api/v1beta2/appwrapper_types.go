@@ -96,10 +96,48 @@ type AppWrapperStatus struct {
 	//+optional
 	Phase AppWrapperPhase `json:"phase,omitempty"`
 
+	// Attempts counts the number of times the AppWrapper has entered the Resuming Phase.
+	// It identifies the current deployment attempt and, unlike the other counters, is incremented by every deployment.
+	//+optional
+	Attempts int32 `json:"attempts,omitempty"`
+
 	// Retries counts the number of times the AppWrapper has entered the Resetting Phase
 	//+optional
 	Retries int32 `json:"resettingCount,omitempty"`
 
+	// PreemptionCount counts the number of times the AppWrapper was preempted by Kueue while its resources were deployed
+	//+optional
+	PreemptionCount int32 `json:"preemptionCount,omitempty"`
+
+	// SuspensionCount counts the number of times the AppWrapper was suspended for reasons other than preemption or failure
+	// while its resources were deployed
+	//+optional
+	SuspensionCount int32 `json:"suspensionCount,omitempty"`
+
+	// SuspendedDuration is the cumulative time the AppWrapper has spent suspended after its resources were first deployed
+	//+optional
+	SuspendedDuration *metav1.Duration `json:"suspendedDuration,omitempty"`
+
+	// ActiveDuration is the cumulative time the resources of the AppWrapper were deployed, excluding the current deployment
+	//+optional
+	ActiveDuration *metav1.Duration `json:"activeDuration,omitempty"`
+
+	// UpdateCount counts the number of times the AppWrapper was restarted to deploy edits to its components
+	//+optional
+	UpdateCount int32 `json:"updateCount,omitempty"`
+
+	// RestartCount counts the number of user-requested restarts that did not count as retries
+	//+optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+
+	// RestartRequest is the value of the restartRequest annotation most recently honoured by the controller
+	//+optional
+	RestartRequest string `json:"restartRequest,omitempty"`
+
+	// DeployedGeneration is the metadata.generation of the AppWrapper whose components were most recently deployed
+	//+optional
+	DeployedGeneration int64 `json:"deployedGeneration,omitempty"`
+
 	// Conditions hold the latest available observations of the AppWrapper current state.
 	//
 	// The type of the condition could be:
@@ -109,6 +147,7 @@ type AppWrapperStatus struct {
 	// - PodsReady: All pods of the contained resources are in the Ready or Succeeded state
 	// - Unhealthy: One or more of the contained resources is unhealthy
 	// - DeletingResources: The contained resources are in the process of being deleted from the cluster
+	// - Preempted: The AppWrapper was most recently suspended because Kueue preempted it
 	//
 	//+optional
 	//+patchMergeKey=type
@@ -119,6 +158,14 @@ type AppWrapperStatus struct {
 
 	// ComponentStatus parallels the Components array in the Spec and tracks the actually deployed resources
 	ComponentStatus []AppWrapperComponentStatus `json:"componentStatus,omitempty"`
+
+	// DispatchedTo is the name of the worker cluster to which a dispatcher has sent the AppWrapper
+	//+optional
+	DispatchedTo string `json:"dispatchedTo,omitempty"`
+
+	// DispatchedSuspend is the value of Spec.Suspend that the dispatcher last propagated to the mirror in the worker cluster
+	//+optional
+	DispatchedSuspend bool `json:"dispatchedSuspend,omitempty"`
 }
 
 // AppWrapperComponentStatus tracks the status of a single managed Component
@@ -173,24 +220,53 @@ const (
 	PodsReady         AppWrapperCondition = "PodsReady"
 	Unhealthy         AppWrapperCondition = "Unhealthy"
 	DeletingResources AppWrapperCondition = "DeletingResources"
+	Preempted         AppWrapperCondition = "Preempted"
 )
 
 const (
-	AdmissionGracePeriodDurationAnnotation = "workload.codeflare.dev.appwrapper/admissionGracePeriodDuration"
-	WarmupGracePeriodDurationAnnotation    = "workload.codeflare.dev.appwrapper/warmupGracePeriodDuration"
-	FailureGracePeriodDurationAnnotation   = "workload.codeflare.dev.appwrapper/failureGracePeriodDuration"
-	RetryPausePeriodDurationAnnotation     = "workload.codeflare.dev.appwrapper/retryPausePeriodDuration"
-	RetryLimitAnnotation                   = "workload.codeflare.dev.appwrapper/retryLimit"
-	ForcefulDeletionGracePeriodAnnotation  = "workload.codeflare.dev.appwrapper/forcefulDeletionGracePeriodDuration"
-	DeletionOnFailureGracePeriodAnnotation = "workload.codeflare.dev.appwrapper/deletionOnFailureGracePeriodDuration"
-	SuccessTTLAnnotation                   = "workload.codeflare.dev.appwrapper/successTTLDuration"
-	TerminalExitCodesAnnotation            = "workload.codeflare.dev.appwrapper/terminalExitCodes"
-	RetryableExitCodesAnnotation           = "workload.codeflare.dev.appwrapper/retryableExitCodes"
+	AdmissionGracePeriodDurationAnnotation  = "workload.codeflare.dev.appwrapper/admissionGracePeriodDuration"
+	WarmupGracePeriodDurationAnnotation     = "workload.codeflare.dev.appwrapper/warmupGracePeriodDuration"
+	FailureGracePeriodDurationAnnotation    = "workload.codeflare.dev.appwrapper/failureGracePeriodDuration"
+	RetryPausePeriodDurationAnnotation      = "workload.codeflare.dev.appwrapper/retryPausePeriodDuration"
+	RetryLimitAnnotation                    = "workload.codeflare.dev.appwrapper/retryLimit"
+	ForcefulDeletionGracePeriodAnnotation   = "workload.codeflare.dev.appwrapper/forcefulDeletionGracePeriodDuration"
+	DeletionOnFailureGracePeriodAnnotation  = "workload.codeflare.dev.appwrapper/deletionOnFailureGracePeriodDuration"
+	SuspensionGracePeriodDurationAnnotation = "workload.codeflare.dev.appwrapper/suspensionGracePeriodDuration"
+	ActiveDeadlineDurationAnnotation        = "workload.codeflare.dev.appwrapper/activeDeadlineDuration"
+	SuspendOnActiveDeadlineAnnotation       = "workload.codeflare.dev.appwrapper/suspendOnActiveDeadline"
+	SuccessTTLAnnotation                    = "workload.codeflare.dev.appwrapper/successTTLDuration"
+	TerminalExitCodesAnnotation             = "workload.codeflare.dev.appwrapper/terminalExitCodes"
+	RetryableExitCodesAnnotation            = "workload.codeflare.dev.appwrapper/retryableExitCodes"
+	WorkerClusterAnnotation                 = "workload.codeflare.dev.appwrapper/workerCluster"
+	MergeIdenticalPodSetsAnnotation         = "workload.codeflare.dev.appwrapper/mergeIdenticalPodSets"
+	RestartRequestAnnotation                = "workload.codeflare.dev.appwrapper/restartRequest"
+	RestartCountsAsRetryAnnotation          = "workload.codeflare.dev.appwrapper/restartCountsAsRetry"
+	HoldAnnotation                          = "workload.codeflare.dev.appwrapper/hold"
 )
 
+// A Namespace may carry the fault tolerance annotations above to supply defaults for the AppWrappers it contains.
+// The same annotations with MaximumAnnotationSuffix appended supply upper bounds for those AppWrappers.
+const MaximumAnnotationSuffix = "Maximum"
+
 const (
 	AppWrapperControllerName = "workload.codeflare.dev/appwrapper-controller"
 	AppWrapperLabel          = "workload.codeflare.dev/appwrapper"
+	// AppWrapperUIDLabel identifies the incarnation of the AppWrapper that created a resource
+	AppWrapperUIDLabel = "workload.codeflare.dev/appwrapper-uid"
+	// AppWrapperAttemptLabel identifies the deployment attempt of the AppWrapper that created a resource
+	AppWrapperAttemptLabel = "workload.codeflare.dev/appwrapper-attempt"
+	// AppWrapperUserInfoAnnotation records the name, uid, and groups of the user who created the AppWrapper
+	// as a JSON-encoded authentication.k8s.io/v1 UserInfo. It is set by the AppWrapper webhook and is immutable.
+	AppWrapperUserInfoAnnotation = "workload.codeflare.dev/userinfo"
+	// SuspensionDeadlineAnnotation is added to the running Pods of a suspending AppWrapper. Its value is the RFC3339 time
+	// at which the AppWrapper's resources will be deleted; workloads can observe it via the downward API and checkpoint.
+	SuspensionDeadlineAnnotation = "workload.codeflare.dev/suspension-deadline"
+	// CheckpointCompleteAnnotation may be set to "true" on a Pod by a workload to indicate that it is ready to be deleted
+	// before the SuspensionDeadline has been reached.
+	CheckpointCompleteAnnotation = "workload.codeflare.dev/checkpoint-complete"
+	// WorkloadDeactivatedAnnotation is set by the AppWrapper controller on an AppWrapper and on its Kueue Workloads
+	// when it deactivates the Workloads to release their quota. Its value is the reason for the deactivation.
+	WorkloadDeactivatedAnnotation = "workload.codeflare.dev/deactivated"
 )
 
 //+kubebuilder:object:root=true
@@ -256,6 +256,16 @@ func (in *AppWrapperSpec) DeepCopy() *AppWrapperSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AppWrapperStatus) DeepCopyInto(out *AppWrapperStatus) {
 	*out = *in
+	if in.SuspendedDuration != nil {
+		in, out := &in.SuspendedDuration, &out.SuspendedDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ActiveDuration != nil {
+		in, out := &in.ActiveDuration, &out.ActiveDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -32,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -71,6 +72,10 @@ func main() {
 	flag.StringVar(&configMapName, "config", "appwrapper-operator-config",
 		"The name of the ConfigMap to load the operator configuration from. "+
 			"If it does not exist, the operator will create and initialise it.")
+	var roleName string
+	flag.StringVar(&roleName, "role", string(controller.RoleAll),
+		"The parts of the operator to run in this process: "+
+			"webhook (admission webhooks only), controller (controllers only), or all.")
 
 	opts := zap.Options{
 		Development: true,
@@ -83,6 +88,11 @@ func main() {
 	ctrl.SetLogger(logger.FilteredLogger(zap.New(zap.UseFlagOptions(&opts))))
 	setupLog.Info("Build info", "version", BuildVersion, "date", BuildDate)
 
+	role, err := controller.ParseRole(roleName)
+	exitOnError(err, "invalid role")
+
+	setupLog.Info("Role", "role", role)
+
 	namespace, err := getNamespace()
 	exitOnError(err, "unable to get operator namespace")
 
@@ -92,6 +102,8 @@ func main() {
 		ControllerManager: config.NewControllerManagerConfig(),
 		WebhooksEnabled:   ptr.To(true),
 	}
+	cfg.AppWrapper.Dispatcher.SecretNamespace = namespace
+	cfg.AppWrapper.ControllerUsername = serviceaccount.MakeUsername(namespace, config.DefaultControllerServiceAccountName)
 
 	k8sConfig, err := ctrl.GetConfig()
 	exitOnError(err, "unable to get client config")
@@ -104,6 +116,7 @@ func main() {
 
 	setupLog.Info("Configuration", "config", cfg)
 	exitOnError(config.ValidateAppWrapperConfig(cfg.AppWrapper), "invalid appwrapper config")
+	exitOnError(config.ValidateControllerManagerConfig(cfg.ControllerManager), "invalid controller manager config")
 
 	tlsOpts := []func(*tls.Config){}
 	if !cfg.ControllerManager.EnableHTTP2 {
@@ -122,8 +135,12 @@ func main() {
 
 	metrics.Register()
 
+	cacheOpts, err := controller.NewCacheOptions(cfg.ControllerManager, cmName)
+	exitOnError(err, "unable to configure cache")
+
 	mgr, err := ctrl.NewManager(k8sConfig, ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOpts,
 		Metrics: metricsserver.Options{
 			BindAddress:    cfg.ControllerManager.Metrics.BindAddress,
 			FilterProvider: filters.WithAuthenticationAndAuthorization,
@@ -135,14 +152,18 @@ func main() {
 			Port:    9443,
 		}),
 		HealthProbeBindAddress: cfg.ControllerManager.Health.BindAddress,
-		LeaderElection:         cfg.ControllerManager.LeaderElection,
-		LeaderElectionID:       "f134c674.codeflare.dev",
+		LeaderElection:         cfg.ControllerManager.LeaderElection && role.RunsControllers(), // webhook replicas all serve requests
+		LeaderElectionID:       cfg.ControllerManager.LeaderElectionID,
 	})
 	exitOnError(err, "unable to start manager")
 
+	sharedConfig := config.NewSharedAppWrapperConfig(cfg.AppWrapper)
+	exitOnError(controller.SetupConfigReloader(mgr, sharedConfig, cmName), "unable to set up configuration reloading")
+
 	certsReady := make(chan struct{})
 
-	if ptr.Deref(cfg.WebhooksEnabled, false) {
+	webhooksEnabled := role.ServesWebhooks(ptr.Deref(cfg.WebhooksEnabled, false))
+	if webhooksEnabled {
 		exitOnError(controller.SetupCertManagement(mgr, cfg.CertManagement, certsReady), "Unable to set up cert rotation")
 	} else {
 		close(certsReady)
@@ -152,14 +173,18 @@ func main() {
 		setupLog.Info("Waiting for certificates to be generated")
 		<-certsReady
 		setupLog.Info("Certs ready")
-		if ptr.Deref(cfg.WebhooksEnabled, false) {
-			exitOnError(controller.SetupWebhooks(mgr, cfg.AppWrapper), "unable to configure webhook")
+		if webhooksEnabled {
+			exitOnError(controller.SetupWebhooks(mgr, sharedConfig), "unable to configure webhook")
+		}
+		if role.RunsControllers() {
+			exitOnError(controller.SetupControllers(mgr, sharedConfig, cfg.ControllerManager), "unable to start controllers")
 		}
-		exitOnError(controller.SetupControllers(mgr, cfg.AppWrapper), "unable to start controllers")
 	}()
 
-	exitOnError(controller.SetupIndexers(ctx, mgr, cfg.AppWrapper), "unable to setup indexers")
-	exitOnError(controller.SetupProbeEndpoints(mgr, certsReady), "unable to setup probe endpoints")
+	if role.RunsControllers() {
+		exitOnError(controller.SetupIndexers(ctx, mgr, cfg.AppWrapper), "unable to setup indexers")
+	}
+	exitOnError(controller.SetupProbeEndpoints(mgr, certsReady, role.ProbeRole(webhooksEnabled)), "unable to setup probe endpoints")
 
 	setupLog.Info("starting manager")
 	exitOnError(mgr.Start(ctx), "problem starting manager")
@@ -200,15 +225,7 @@ func loadIntoOrCreate(ctx context.Context, k8sClient client.Client, cmName types
 		return err
 	}
 
-	if len(configMap.Data) != 1 {
-		return fmt.Errorf("cannot resolve config from ConfigMap %s/%s", configMap.Namespace, configMap.Name)
-	}
-
-	for _, data := range configMap.Data {
-		return yaml.Unmarshal([]byte(data), cfg)
-	}
-
-	return nil
+	return config.ParseConfigMap(configMap, cfg)
 }
 
 func exitOnError(err error, msg string) {
@@ -0,0 +1,57 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// genrbac generates the kubebuilder RBAC markers that grant the AppWrapper controller
+// permission to manage the default wrappable kinds, keeping the controller's ClusterRole
+// consistent with config.DefaultWrappableKinds.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/project-codeflare/appwrapper/pkg/config"
+)
+
+func main() {
+	var header, output, pkg string
+	flag.StringVar(&header, "header", "hack/boilerplate.go.txt", "file containing the license header")
+	flag.StringVar(&output, "o", "internal/controller/appwrapper/zz_generated.rbac.go", "output file")
+	flag.StringVar(&pkg, "package", "appwrapper", "package name of the output file")
+	flag.Parse()
+
+	boilerplate, err := os.ReadFile(header)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(bytes.TrimSpace(boilerplate))
+	buf.WriteString("\n\n// Code generated by hack/genrbac. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %v\n\n", pkg)
+	buf.WriteString("// permission for wrapped resources: generated from config.DefaultWrappableKinds\n")
+	for _, marker := range config.RBACMarkers(config.DefaultWrappableKinds) {
+		buf.WriteString(marker + "\n")
+	}
+
+	if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -19,8 +19,8 @@ package appwrapper
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
+	"net/http"
+	"sync"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -29,16 +29,23 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
 	"github.com/project-codeflare/appwrapper/internal/metrics"
@@ -55,7 +62,17 @@ type AppWrapperReconciler struct {
 	client.Client
 	Recorder events.EventRecorder
 	Scheme   *runtime.Scheme
-	Config   *config.AppWrapperConfig
+	Config   *config.SharedAppWrapperConfig
+	Events   chan event.GenericEvent // event channel for NodeHealthMonitor to trigger reconciliation of AppWrappers
+
+	// NamespaceSelector restricts reconciliation to AppWrappers whose namespace has matching labels (nil matches all)
+	NamespaceSelector labels.Selector
+
+	// ImpersonationConfig, if non-nil, is used to create components while impersonating the AppWrapper's creator
+	ImpersonationConfig *rest.Config
+
+	impersonationMutex     sync.Mutex
+	impersonationTransport http.RoundTripper // built from ImpersonationConfig on first use and shared by all impersonating clients
 }
 
 type podStatusSummary struct {
@@ -82,15 +99,10 @@ type componentStatusSummary struct {
 // permission for events
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;watch;update;patch
 
-// permission for wrapped resources: pods, services, jobs, podgroups, pytorchjobs, rayclusters, jobsets
-//+kubebuilder:rbac:groups="",resources=pods;services,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=scheduling.sigs.k8s.io,resources=podgroups,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=scheduling.x-k8s.io,resources=podgroups,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=kubeflow.org,resources=pytorchjobs,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=ray.io,resources=rayclusters;rayjobs,verbs=get;list;watch;create;delete
-//+kubebuilder:rbac:groups=jobset.x-k8s.io,resources=jobsets,verbs=get;list;watch;create;delete
+// permission to annotate the pods of suspending appwrappers
+//+kubebuilder:rbac:groups="",resources=pods,verbs=patch
+
+// permission for wrapped resources is generated from config.DefaultWrappableKinds into zz_generated.rbac.go by make genrbac
 
 // Reconcile reconciles an appwrapper
 // Please see [aw-states] for documentation of this method.
@@ -104,11 +116,16 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	// stop reconciliation if managed by another controller
-	if aw.Spec.ManagedBy != nil && *aw.Spec.ManagedBy != awv1beta2.AppWrapperControllerName {
+	// stop reconciliation if managed by another controller (AppWrappers without managedBy belong to the default controller)
+	if ptr.Deref(aw.Spec.ManagedBy, awv1beta2.AppWrapperControllerName) != r.Config.Get().ControllerName {
 		return ctrl.Result{}, nil
 	}
 
+	// stop reconciliation if the AppWrapper's namespace belongs to another shard
+	if selected, err := namespaceSelected(ctx, r.Client, r.NamespaceSelector, aw.Namespace); err != nil || !selected {
+		return ctrl.Result{}, err
+	}
+
 	// handle deletion first
 	if !aw.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(aw, AppWrapperFinalizer) {
@@ -169,9 +186,43 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspended)
 
 	case awv1beta2.AppWrapperSuspended: // no components deployed
+		// the webhook permits edits to the components while Suspended; recompute their status if they changed
+		if outdated, err := utils.ComponentStatusOutdated(aw); err != nil {
+			return ctrl.Result{}, err
+		} else if outdated {
+			orig := copyForStatusPatch(aw)
+			aw.Status.ComponentStatus = nil
+			if err := utils.EnsureComponentStatusInitialized(aw); err != nil {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ComponentsChanged", string(awv1beta2.AppWrapperSuspended), "Component status reset after the components were edited")
+			return ctrl.Result{}, r.Status().Patch(ctx, aw, client.MergeFrom(orig))
+		}
+
+		// a held AppWrapper or one suspended at its active deadline must not keep the quota Kueue reserved for it;
+		// the Kueue Workloads are only listed when this changes (see syncWorkloadActive)
+		inactiveReason := ""
+		if held(aw) {
+			inactiveReason = heldReason
+		} else if r.suspendedAtActiveDeadline(ctx, aw) {
+			inactiveReason = "ActiveDeadlineExceeded"
+		}
+		r.syncWorkloadActive(ctx, aw, inactiveReason)
+
 		if aw.Spec.Suspend {
 			return ctrl.Result{}, nil // remain suspended
 		}
+		if held(aw) {
+			return ctrl.Result{}, nil // remain suspended until the user releases the hold
+		}
+		if deadline := r.activeDeadline(ctx, aw); deadline > 0 && activeDuration(aw) >= deadline {
+			if r.suspendOnActiveDeadline(ctx, aw) {
+				return ctrl.Result{}, nil // remain suspended until the user extends the deadline
+			}
+			orig := copyForStatusPatch(aw)
+			r.activeDeadlineExceeded(aw, deadline)
+			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperFailed)
+		}
 
 		// ensure our finalizer is present before we deploy any resources
 		if controllerutil.AddFinalizer(aw, AppWrapperFinalizer) {
@@ -181,8 +232,10 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			log.FromContext(ctx).Info("Finalizer Added")
 		}
 
-		// begin deployment
+		// begin deployment; the new deployment honours any outstanding restart request
 		orig := copyForStatusPatch(aw)
+		aw.Status.RestartRequest = aw.Annotations[awv1beta2.RestartRequestAnnotation]
+		recordResumption(aw)
 		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 			Type:    string(awv1beta2.QuotaReserved),
 			Status:  metav1.ConditionTrue,
@@ -211,18 +264,20 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	case awv1beta2.AppWrapperResuming: // deploying components
 		if aw.Spec.Suspend {
-			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ExternallySuspended", string(awv1beta2.AppWrapperSuspending), "Externally suspended while in the Resuming phase")
-			return ctrl.Result{}, r.transitionToPhase(ctx, copyForStatusPatch(aw), aw, awv1beta2.AppWrapperSuspending) // abort deployment
+			orig := copyForStatusPatch(aw)
+			r.recordSuspension(ctx, aw, awv1beta2.AppWrapperResuming)
+			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspending) // abort deployment
 		}
-		err, fatal := r.createComponents(ctx, aw) // NOTE: createComponents applies patches to aw.Status incrementally as resources are created
 		orig := copyForStatusPatch(aw)
+		aw.Status.DeployedGeneration = aw.Generation
+		err, fatal := r.createComponents(ctx, aw) // NOTE: the outcome of createComponents is only recorded in aw.Status and must be patched below
 		if err != nil {
 			if !fatal {
 				startTime := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed)).LastTransitionTime
 				graceDuration := r.admissionGraceDuration(ctx, aw)
 				if time.Now().Before(startTime.Add(graceDuration)) {
 					// be patient; non-fatal error; requeue and keep trying
-					return ctrl.Result{RequeueAfter: 1 * time.Second}, nil
+					return requeueAfter(1*time.Second, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 				}
 			}
 			detailMsg := fmt.Sprintf("error creating components: %v", err)
@@ -244,10 +299,59 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	case awv1beta2.AppWrapperRunning: // components deployed
 		orig := copyForStatusPatch(aw)
 		if aw.Spec.Suspend {
-			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ExternallySuspended", string(awv1beta2.AppWrapperSuspending), "Externally suspended while in the Running phase")
+			r.recordSuspension(ctx, aw, awv1beta2.AppWrapperRunning)
 			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspending) // begin undeployment
 		}
 
+		// AppWrappers deployed by an earlier version of the controller did not record the deployed generation.
+		// Their components could not be edited while Running, so the current generation is the deployed one.
+		if aw.Status.DeployedGeneration == 0 {
+			aw.Status.DeployedGeneration = aw.Generation
+		}
+
+		// The components were edited after they were deployed; restart via Suspending and Resuming to deploy the new ones.
+		// A restart is not a retry, so it does not count against the RetryLimit.
+		if aw.Generation != aw.Status.DeployedGeneration {
+			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ComponentsUpdated", string(awv1beta2.AppWrapperSuspending), "Restarting to deploy the updated components")
+			aw.Status.UpdateCount += 1
+			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspending)
+		}
+
+		// Honour a new user-requested restart by resetting the AppWrapper
+		if request := aw.Annotations[awv1beta2.RestartRequestAnnotation]; request != "" && request != aw.Status.RestartRequest {
+			aw.Status.RestartRequest = request
+			detailMsg := fmt.Sprintf("Restart %v requested by user", request)
+			meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+				Type:    string(awv1beta2.Unhealthy),
+				Status:  metav1.ConditionTrue,
+				Reason:  "RestartRequested",
+				Message: detailMsg,
+			})
+			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "RestartRequested", string(awv1beta2.AppWrapperResetting), "%s", detailMsg)
+			// A requested restart never fails the AppWrapper: once its retries are exhausted, restarts are no longer charged to them
+			if r.restartCountsAsRetry(ctx, aw) && aw.Status.Retries < r.retryLimit(ctx, aw) {
+				aw.Status.Retries += 1
+			} else {
+				aw.Status.RestartCount += 1
+			}
+			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperResetting)
+		}
+
+		// Enforce the active deadline, which bounds the cumulative time the resources are deployed across all resets
+		recheckAfter := time.Minute
+		if deadline := r.activeDeadline(ctx, aw); deadline > 0 {
+			remaining := deadline - activeDuration(aw)
+			if remaining <= 0 {
+				r.activeDeadlineExceeded(aw, deadline)
+				if r.suspendOnActiveDeadline(ctx, aw) {
+					aw.Status.SuspensionCount += 1
+					return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspending)
+				}
+				return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperFailed)
+			}
+			recheckAfter = min(recheckAfter, remaining)
+		}
+
 		// Gather status information at the Component and Pod level.
 		compStatus, err := r.getComponentStatus(ctx, aw)
 		if err != nil {
@@ -347,7 +451,7 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 				Reason:  "SufficientPodsReady",
 				Message: fmt.Sprintf("%v pods running; %v pods succeeded", podStatus.running, podStatus.succeeded),
 			})
-			return requeueAfter(time.Minute, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
+			return requeueAfter(recheckAfter, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 		}
 
 		// Not ready yet; either continue to wait or giveup if the warmup period has expired
@@ -361,7 +465,7 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			graceDuration = r.admissionGraceDuration(ctx, aw)
 		}
 		if time.Now().Before(whenDeployed.Add(graceDuration)) {
-			return requeueAfter(5*time.Second, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
+			return requeueAfter(min(5*time.Second, recheckAfter), r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 		} else {
 			meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 				Type:    string(awv1beta2.Unhealthy),
@@ -375,23 +479,36 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	case awv1beta2.AppWrapperSuspending: // undeploying components
 		orig := copyForStatusPatch(aw)
+		reason, message := suspensionCause(aw)
+		if reason == "" {
+			reason, message = string(awv1beta2.AppWrapperSuspended), "Suspend is true"
+		}
 		// finish undeploying components irrespective of desired state (suspend bit)
 		if meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed)) {
+			// give the workload an opportunity to checkpoint unless the deletion of its components has already begun
+			// (a workload evicted because its Pods did not become ready has nothing to checkpoint)
+			if gracePeriod := r.suspensionGraceDuration(ctx, aw); gracePeriod > 0 && !meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.DeletingResources)) &&
+				!evictedAsUnhealthy(aw) {
+				if done, retryAfter := r.signalSuspension(ctx, aw, gracePeriod); !done {
+					return requeueAfter(retryAfter, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
+				}
+			}
 			if !r.deleteComponents(ctx, aw) {
 				return requeueAfter(5*time.Second, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 			}
+			recordUndeployment(aw)
 			meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 				Type:    string(awv1beta2.ResourcesDeployed),
 				Status:  metav1.ConditionFalse,
-				Reason:  string(awv1beta2.AppWrapperSuspended),
-				Message: "Suspend is true",
+				Reason:  reason,
+				Message: message,
 			})
 		}
 		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 			Type:    string(awv1beta2.QuotaReserved),
 			Status:  metav1.ConditionFalse,
-			Reason:  string(awv1beta2.AppWrapperSuspended),
-			Message: "Suspend is true",
+			Reason:  reason,
+			Message: message,
 		})
 		clearCondition(aw, awv1beta2.PodsReady, string(awv1beta2.AppWrapperSuspended), "")
 		clearCondition(aw, awv1beta2.Unhealthy, string(awv1beta2.AppWrapperSuspended), "")
@@ -400,7 +517,7 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	case awv1beta2.AppWrapperResetting:
 		orig := copyForStatusPatch(aw)
 		if aw.Spec.Suspend {
-			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ExternallySuspended", string(awv1beta2.AppWrapperSuspending), "Externally suspended while in the Resetting phase")
+			r.recordSuspension(ctx, aw, awv1beta2.AppWrapperResetting)
 			return ctrl.Result{}, r.transitionToPhase(ctx, orig, aw, awv1beta2.AppWrapperSuspending) // Suspending trumps Resetting
 		}
 
@@ -409,6 +526,7 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			if !r.deleteComponents(ctx, aw) {
 				return requeueAfter(5*time.Second, r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 			}
+			recordUndeployment(aw)
 			meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 				Type:    string(awv1beta2.ResourcesDeployed),
 				Status:  metav1.ConditionFalse,
@@ -425,6 +543,11 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if now.Before(deadline) {
 			return requeueAfter(deadline.Sub(now), r.Status().Patch(ctx, aw, client.MergeFrom(orig)))
 		}
+		if held(aw) {
+			r.syncWorkloadActive(ctx, aw, heldReason)
+			return ctrl.Result{}, r.Status().Patch(ctx, aw, client.MergeFrom(orig)) // remain reset until the user releases the hold
+		}
+		r.syncWorkloadActive(ctx, aw, "")
 
 		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
 			Type:    string(awv1beta2.ResourcesDeployed),
@@ -510,6 +633,9 @@ func (r *AppWrapperReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 }
 
 func (r *AppWrapperReconciler) transitionToPhase(ctx context.Context, orig *awv1beta2.AppWrapper, modified *awv1beta2.AppWrapper, phase awv1beta2.AppWrapperPhase) error {
+	if phase == awv1beta2.AppWrapperResuming && modified.Status.Phase != awv1beta2.AppWrapperResuming {
+		beginAttempt(modified)
+	}
 	modified.Status.Phase = phase
 	if err := r.Status().Patch(ctx, modified, client.MergeFrom(orig)); err != nil {
 		return err
@@ -531,10 +657,8 @@ func (r *AppWrapperReconciler) resetOrFail(ctx context.Context, orig *awv1beta2.
 
 //gocyclo:ignore
 func (r *AppWrapperReconciler) getPodStatus(ctx context.Context, aw *awv1beta2.AppWrapper) (*podStatusSummary, error) {
-	pods := &v1.PodList{}
-	if err := r.List(ctx, pods,
-		client.InNamespace(aw.Namespace),
-		client.MatchingLabels{awv1beta2.AppWrapperLabel: aw.Name}); err != nil {
+	pods, err := r.listAppWrapperPods(ctx, aw)
+	if err != nil {
 		return nil, err
 	}
 	pc, err := utils.ExpectedPodCount(aw)
@@ -542,9 +666,14 @@ func (r *AppWrapperReconciler) getPodStatus(ctx context.Context, aw *awv1beta2.A
 		return nil, err
 	}
 	summary := &podStatusSummary{expected: pc}
-	checkNoExecuteNodes := r.Config.Autopilot != nil && r.Config.Autopilot.MonitorNodes
+	autopilot := r.Config.Get().Autopilot
+	checkNoExecuteNodes := autopilot != nil && autopilot.MonitorNodes
 
+	currentAttempt := attemptIndex(aw)
 	for _, pod := range pods.Items {
+		if attempt, ok := pod.Labels[awv1beta2.AppWrapperAttemptLabel]; ok && attempt != currentAttempt {
+			continue // a lingering Pod of a previous attempt
+		}
 		switch pod.Status.Phase {
 		case v1.PodPending:
 			summary.pending += 1
@@ -762,129 +891,153 @@ func (r *AppWrapperReconciler) getComponentStatus(ctx context.Context, aw *awv1b
 func (r *AppWrapperReconciler) limitDuration(desired time.Duration) time.Duration {
 	if desired < 0 {
 		return 0 * time.Second
-	} else if desired > r.Config.FaultTolerance.GracePeriodMaximum {
-		return r.Config.FaultTolerance.GracePeriodMaximum
+	} else if maximum := r.Config.Get().FaultTolerance.GracePeriodMaximum; desired > maximum {
+		return maximum
 	} else {
 		return desired
 	}
 }
 
 func (r *AppWrapperReconciler) admissionGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.AdmissionGracePeriodDurationAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed admission grace period annotation; using default", "annotation", userPeriod)
-		}
-	}
-	return r.limitDuration(r.Config.FaultTolerance.AdmissionGracePeriod)
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.AdmissionGracePeriodDurationAnnotation, r.Config.Get().FaultTolerance.AdmissionGracePeriod))
 }
 
 func (r *AppWrapperReconciler) warmupGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.WarmupGracePeriodDurationAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed warmup grace period annotation; using default", "annotation", userPeriod)
-		}
-	}
-	return r.limitDuration(r.Config.FaultTolerance.WarmupGracePeriod)
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.WarmupGracePeriodDurationAnnotation, r.Config.Get().FaultTolerance.WarmupGracePeriod))
 }
 
 func (r *AppWrapperReconciler) failureGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.FailureGracePeriodDurationAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed failure grace period annotation; using default", "annotation", userPeriod)
-		}
-	}
-	return r.limitDuration(r.Config.FaultTolerance.FailureGracePeriod)
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.FailureGracePeriodDurationAnnotation, r.Config.Get().FaultTolerance.FailureGracePeriod))
 }
 
 func (r *AppWrapperReconciler) retryLimit(ctx context.Context, aw *awv1beta2.AppWrapper) int32 {
-	if userLimit, ok := aw.Annotations[awv1beta2.RetryLimitAnnotation]; ok {
-		if limit, err := strconv.Atoi(userLimit); err == nil {
-			return int32(limit)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed retry limit annotation; using default", "annotation", userLimit)
-		}
-	}
-	return r.Config.FaultTolerance.RetryLimit
+	return r.resolveLimit(ctx, aw, awv1beta2.RetryLimitAnnotation, r.Config.Get().FaultTolerance.RetryLimit)
 }
 
 func (r *AppWrapperReconciler) retryPauseDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.RetryPausePeriodDurationAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed retry pause annotation; using default", "annotation", userPeriod)
-		}
-	}
-	return r.limitDuration(r.Config.FaultTolerance.RetryPausePeriod)
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.RetryPausePeriodDurationAnnotation, r.Config.Get().FaultTolerance.RetryPausePeriod))
 }
 
 func (r *AppWrapperReconciler) forcefulDeletionGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.ForcefulDeletionGracePeriodAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed forceful deletion period annotation; using default", "annotation", userPeriod)
-		}
-	}
-	return r.limitDuration(r.Config.FaultTolerance.ForcefulDeletionGracePeriod)
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.ForcefulDeletionGracePeriodAnnotation, r.Config.Get().FaultTolerance.ForcefulDeletionGracePeriod))
+}
+
+func (r *AppWrapperReconciler) suspensionGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.SuspensionGracePeriodDurationAnnotation, r.Config.Get().FaultTolerance.SuspensionGracePeriod))
 }
 
 func (r *AppWrapperReconciler) deletionOnFailureGraceDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.DeletionOnFailureGracePeriodAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			return r.limitDuration(duration)
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed deletion on failure grace period annotation; using default of 0", "annotation", userPeriod)
-		}
-	}
-	return 0 * time.Second
+	return r.limitDuration(r.resolveDuration(ctx, aw, awv1beta2.DeletionOnFailureGracePeriodAnnotation, 0*time.Second))
 }
 
 func (r *AppWrapperReconciler) timeToLiveAfterSucceededDuration(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
-	if userPeriod, ok := aw.Annotations[awv1beta2.SuccessTTLAnnotation]; ok {
-		if duration, err := time.ParseDuration(userPeriod); err == nil {
-			if duration > 0 && duration < r.Config.FaultTolerance.SuccessTTL {
-				return duration
-			}
-		} else {
-			log.FromContext(ctx).Error(err, "Malformed successTTL annotation; using default", "annotation", userPeriod)
+	ceiling := r.Config.Get().FaultTolerance.SuccessTTL
+	if duration := r.resolveDuration(ctx, aw, awv1beta2.SuccessTTLAnnotation, ceiling); duration > 0 && duration < ceiling {
+		return duration
+	}
+	return ceiling
+}
+
+// activeDeadline returns the bound on the cumulative time the resources of aw may be deployed; zero means unbounded.
+// Because zero is unbounded, a maximum replaces (rather than bounds) a zero deadline.
+func (r *AppWrapperReconciler) activeDeadline(ctx context.Context, aw *awv1beta2.AppWrapper) time.Duration {
+	deadline := r.resolveDuration(ctx, aw, awv1beta2.ActiveDeadlineDurationAnnotation, r.Config.Get().FaultTolerance.ActiveDeadline)
+	if nsMaximum, ok := r.namespacePolicy(ctx, aw)[awv1beta2.ActiveDeadlineDurationAnnotation+awv1beta2.MaximumAnnotationSuffix]; ok {
+		if maximum, err := utils.ParseDurationAnnotation(nsMaximum); err == nil && maximum > 0 && deadline <= 0 {
+			deadline = maximum
 		}
 	}
-	return r.Config.FaultTolerance.SuccessTTL
+	if maximum := r.Config.Get().FaultTolerance.ActiveDeadlineMaximum; maximum > 0 && (deadline <= 0 || deadline > maximum) {
+		deadline = maximum
+	}
+	return max(deadline, 0)
+}
+
+func (r *AppWrapperReconciler) suspendOnActiveDeadline(_ context.Context, aw *awv1beta2.AppWrapper) bool {
+	suspend, err := utils.ParseBoolAnnotation(aw.Annotations[awv1beta2.SuspendOnActiveDeadlineAnnotation])
+	return err == nil && suspend
+}
+
+// suspendedAtActiveDeadline returns true if aw has exceeded its active deadline and is to remain suspended until it is extended.
+// The cheap checks come first because it is evaluated for every Suspended AppWrapper, most of which were never deployed.
+func (r *AppWrapperReconciler) suspendedAtActiveDeadline(ctx context.Context, aw *awv1beta2.AppWrapper) bool {
+	if aw.Status.ActiveDuration == nil || !r.suspendOnActiveDeadline(ctx, aw) {
+		return false // never deployed or not suspended at the deadline
+	}
+	deadline := r.activeDeadline(ctx, aw)
+	return deadline > 0 && activeDuration(aw) >= deadline
+}
+
+// activeDeadlineExceeded records that aw has exceeded its active deadline.
+// The Preempted condition is updated so that a subsequent suspension reports the deadline as its cause.
+func (r *AppWrapperReconciler) activeDeadlineExceeded(aw *awv1beta2.AppWrapper, deadline time.Duration) {
+	detailMsg := fmt.Sprintf("Resources were deployed for longer than the active deadline of %v", deadline)
+	meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+		Type:    string(awv1beta2.Unhealthy),
+		Status:  metav1.ConditionTrue,
+		Reason:  "ActiveDeadlineExceeded",
+		Message: detailMsg,
+	})
+	meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+		Type:    string(awv1beta2.Preempted),
+		Status:  metav1.ConditionFalse,
+		Reason:  "ActiveDeadlineExceeded",
+		Message: detailMsg,
+	})
+	r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ActiveDeadlineExceeded", string(awv1beta2.Unhealthy), "%s", detailMsg)
+}
+
+// activeDuration returns the cumulative time the resources of aw have been deployed, including its current deployment
+func activeDuration(aw *awv1beta2.AppWrapper) time.Duration {
+	active := time.Duration(0)
+	if aw.Status.ActiveDuration != nil {
+		active = aw.Status.ActiveDuration.Duration
+	}
+	if cond := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed)); cond != nil && cond.Status == metav1.ConditionTrue {
+		active += time.Since(cond.LastTransitionTime.Time)
+	}
+	return active
+}
+
+// recordUndeployment adds the duration of the current deployment of aw to its ActiveDuration.
+// It must be called before the ResourcesDeployed condition is set to false.
+func recordUndeployment(aw *awv1beta2.AppWrapper) {
+	if meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed)) {
+		aw.Status.ActiveDuration = &metav1.Duration{Duration: activeDuration(aw).Round(time.Second)}
+	}
 }
 
 func (r *AppWrapperReconciler) terminalExitCodes(_ context.Context, aw *awv1beta2.AppWrapper) []int {
-	ans := []int{}
 	if exitCodeAnn, ok := aw.Annotations[awv1beta2.TerminalExitCodesAnnotation]; ok {
-		exitCodes := strings.Split(exitCodeAnn, ",")
-		for _, str := range exitCodes {
-			exitCode, err := strconv.Atoi(str)
-			if err == nil {
-				ans = append(ans, exitCode)
-			}
-		}
+		exitCodes, _ := utils.ParseExitCodesAnnotation(exitCodeAnn) // malformed codes are ignored
+		return exitCodes
 	}
-	return ans
+	return []int{}
 }
 
 func (r *AppWrapperReconciler) retryableExitCodes(_ context.Context, aw *awv1beta2.AppWrapper) []int {
-	ans := []int{}
 	if exitCodeAnn, ok := aw.Annotations[awv1beta2.RetryableExitCodesAnnotation]; ok {
-		exitCodes := strings.Split(exitCodeAnn, ",")
-		for _, str := range exitCodes {
-			exitCode, err := strconv.Atoi(str)
-			if err == nil {
-				ans = append(ans, exitCode)
-			}
+		exitCodes, _ := utils.ParseExitCodesAnnotation(exitCodeAnn) // malformed codes are ignored
+		return exitCodes
+	}
+	return []int{}
+}
+
+func (r *AppWrapperReconciler) restartCountsAsRetry(_ context.Context, aw *awv1beta2.AppWrapper) bool {
+	if value, ok := aw.Annotations[awv1beta2.RestartCountsAsRetryAnnotation]; ok {
+		if counts, err := utils.ParseBoolAnnotation(value); err == nil {
+			return counts
 		}
 	}
-	return ans
+	return true // by default, user-requested restarts count as retries
+}
+
+// heldReason is the reason recorded when the Kueue Workload of a held AppWrapper is deactivated
+const heldReason = "Held"
+
+func held(aw *awv1beta2.AppWrapper) bool {
+	hold, err := utils.ParseBoolAnnotation(aw.Annotations[awv1beta2.HoldAnnotation])
+	return err == nil && hold
 }
 
 func clearCondition(aw *awv1beta2.AppWrapper, condition awv1beta2.AppWrapperCondition, reason string, message string) {
@@ -911,11 +1064,17 @@ func (r *AppWrapperReconciler) podMapFunc(ctx context.Context, obj client.Object
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AppWrapperReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&awv1beta2.AppWrapper{}).
-		Watches(&v1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podMapFunc)).
-		Named(awv1beta2.AppWrapperKind).
-		Complete(r)
+		Watches(&v1.Pod{}, handler.EnqueueRequestsFromMapFunc(r.podMapFunc))
+	if r.Events != nil {
+		b = b.WatchesRawSource(source.Channel(r.Events, &handler.EnqueueRequestForObject{}))
+	}
+	if r.NamespaceSelector != nil {
+		b = b.Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(appWrappersInNamespace(r.Client)),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	return b.Named(awv1beta2.AppWrapperKind).Complete(r)
 }
 
 // copyForStatusPatch returns an AppWrapper with an empty Spec and a DeepCopy of orig's Status for use in a subsequent Status().Patch(...) call
@@ -17,14 +17,18 @@ limitations under the License.
 package appwrapper
 
 import (
+	"bytes"
+	"encoding/json"
 	"time"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -65,10 +69,10 @@ var _ = Describe("AppWrapper Controller", func() {
 		awConfig.Autopilot.ResourceTaints["nvidia.com/gpu"] = append(awConfig.Autopilot.ResourceTaints["nvidia.com/gpu"], v1.Taint{Key: "extra2", Value: "test2", Effect: v1.TaintEffectPreferNoSchedule})
 
 		awReconciler = &AppWrapperReconciler{
-			Client:   k8sClient,
+			Client:   k8sIndexedClient,
 			Recorder: &events.FakeRecorder{},
 			Scheme:   k8sClient.Scheme(),
-			Config:   awConfig,
+			Config:   config.NewSharedAppWrapperConfig(awConfig),
 		}
 
 		By("Reconciling: Empty -> Suspended")
@@ -174,7 +178,7 @@ var _ = Describe("AppWrapper Controller", func() {
 			Expect(p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution).ShouldNot(BeNil())
 			Expect(p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).Should(HaveLen(1))
 			mes := p.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions
-			for _, taint := range awReconciler.Config.Autopilot.ResourceTaints["nvidia.com/gpu"] {
+			for _, taint := range awReconciler.Config.Get().Autopilot.ResourceTaints["nvidia.com/gpu"] {
 				found := false
 				if taint.Effect == v1.TaintEffectNoExecute || taint.Effect == v1.TaintEffectNoSchedule {
 					for _, me := range mes {
@@ -281,6 +285,9 @@ var _ = Describe("AppWrapper Controller", func() {
 		aw = getAppWrapper(awName)
 		Expect(aw.Spec.Suspend).Should(BeTrue())
 		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.SuspensionCount).Should(Equal(int32(1)))
+		Expect(aw.Status.PreemptionCount).Should(Equal(int32(0)), "suspensions without a Kueue eviction are not preemptions")
+		Expect(meta.IsStatusConditionFalse(aw.Status.Conditions, string(awv1beta2.Preempted))).Should(BeTrue())
 		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))).Should(BeTrue())
 		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.QuotaReserved))).Should(BeTrue())
 
@@ -295,9 +302,498 @@ var _ = Describe("AppWrapper Controller", func() {
 		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
 		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))).Should(BeFalse())
 		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.QuotaReserved))).Should(BeFalse())
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.QuotaReserved)).Reason).Should(Equal(string(awv1beta2.AppWrapperSuspended)))
 		podStatus, err := awReconciler.getPodStatus(ctx, aw)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(podStatus.failed + podStatus.succeeded + podStatus.running + podStatus.pending).Should(Equal(int32(0)))
+
+		By("Reconciling: Suspended -> Resuming records the time spent suspended")
+		aw.Spec.Suspend = false
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		Expect(aw.Status.SuspendedDuration).ShouldNot(BeNil())
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.Preempted)).Reason).Should(Equal(string(awv1beta2.AppWrapperResuming)))
+	})
+
+	It("Kueue preemptions are counted as preemptions and the time spent suspended is accumulated", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+		wl := createWorkload(getAppWrapper(awName))
+		defer func() { Expect(k8sClient.Delete(ctx, wl)).To(Succeed()) }()
+
+		By("Kueue preempts the Workload")
+		evictWorkload(wl, kueueEvictedByPreemption)
+		aw := getAppWrapper(awName)
+		aw.Spec.Suspend = true
+		Expect(utils.ClearPodSetInfos(aw)).To(BeTrue())
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.PreemptionCount).Should(Equal(int32(1)))
+		Expect(aw.Status.SuspensionCount).Should(Equal(int32(0)))
+		Expect(aw.Status.Retries).Should(Equal(int32(0)))
+		preempted := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.Preempted))
+		Expect(preempted.Status).Should(Equal(metav1.ConditionTrue))
+		Expect(preempted.Reason).Should(Equal(kueueEvictedByPreemption))
+
+		By("Reconciling: Suspending -> Suspended")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.QuotaReserved)).Reason).Should(Equal(kueueEvictedByPreemption))
+
+		By("Reconciling: Suspended -> Resuming adds the time spent suspended to that of earlier suspensions")
+		orig := copyForStatusPatch(aw)
+		aw.Status.SuspendedDuration = &metav1.Duration{Duration: time.Hour}
+		Expect(k8sClient.Status().Patch(ctx, aw, client.MergeFrom(orig))).To(Succeed())
+		aw = getAppWrapper(awName)
+		aw.Spec.Suspend = false
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		Expect(aw.Status.SuspendedDuration.Duration).Should(BeNumerically(">=", time.Hour))
+		Expect(meta.IsStatusConditionFalse(aw.Status.Conditions, string(awv1beta2.Preempted))).Should(BeTrue())
+	})
+
+	It("Workloads evicted because their Pods did not become ready are retried without a checkpoint window", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+		wl := createWorkload(getAppWrapper(awName))
+		defer func() { Expect(k8sClient.Delete(ctx, wl)).To(Succeed()) }()
+
+		By("Kueue evicts the Workload of an AppWrapper with a SuspensionGracePeriod")
+		evictWorkload(wl, kueueEvictedByPodsReadyTimeout)
+		aw := getAppWrapper(awName)
+		aw.Annotations = map[string]string{awv1beta2.SuspensionGracePeriodDurationAnnotation: "1h"}
+		aw.Spec.Suspend = true
+		Expect(utils.ClearPodSetInfos(aw)).To(BeTrue())
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.Retries).Should(Equal(int32(1)))
+		Expect(aw.Status.PreemptionCount).Should(Equal(int32(0)))
+		Expect(aw.Status.SuspensionCount).Should(Equal(int32(0)))
+		Expect(evictedAsUnhealthy(aw)).Should(BeTrue())
+
+		By("Reconciling: Suspending -> Suspended without waiting for a checkpoint")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.DeletingResources)).Reason).ShouldNot(Equal("SuspensionGracePeriod"))
+	})
+
+	It("Evictions of Resetting Workloads because their Pods did not become ready are not counted twice", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+		wl := createWorkload(getAppWrapper(awName))
+		defer func() { Expect(k8sClient.Delete(ctx, wl)).To(Succeed()) }()
+
+		By("Resetting the AppWrapper as a retry")
+		aw := getAppWrapper(awName)
+		orig := copyForStatusPatch(aw)
+		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+			Type:   string(awv1beta2.Unhealthy),
+			Status: metav1.ConditionTrue,
+			Reason: "InsufficientPodsReady",
+		})
+		Expect(awReconciler.resetOrFail(ctx, orig, aw, false, 1)).To(Succeed())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResetting))
+		Expect(aw.Status.Retries).Should(Equal(int32(1)))
+
+		By("Kueue evicts the Workload for the same failure")
+		evictWorkload(wl, kueueEvictedByPodsReadyTimeout)
+		aw.Spec.Suspend = true
+		Expect(utils.ClearPodSetInfos(aw)).To(BeTrue())
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.Retries).Should(Equal(int32(1)), "the failure was counted when the AppWrapper entered Resetting")
+		Expect(aw.Status.SuspensionCount).Should(Equal(int32(0)))
+	})
+
+	It("Suspending Workloads are given a window to checkpoint", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Invoking Suspend on an AppWrapper with a SuspensionGracePeriod")
+		aw := getAppWrapper(awName)
+		aw.Annotations = map[string]string{awv1beta2.SuspensionGracePeriodDurationAnnotation: "1h"}
+		aw.Spec.Suspend = true
+		Expect(utils.ClearPodSetInfos(aw)).To(BeTrue())
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+
+		By("Reconciling: Suspending -> Suspending while the workload checkpoints")
+		result, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeNumerically(">", 0))
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))).Should(BeTrue())
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.DeletingResources)).Reason).Should(Equal("SuspensionGracePeriod"))
+		pods := getPods(aw)
+		Expect(pods).Should(HaveLen(2))
+		for _, p := range pods {
+			Expect(p.Annotations).Should(HaveKey(awv1beta2.SuspensionDeadlineAnnotation))
+		}
+
+		By("Simulating the workload reporting its checkpoints are complete")
+		for _, p := range pods {
+			patch := client.MergeFrom(p.DeepCopy())
+			p.Annotations[awv1beta2.CheckpointCompleteAnnotation] = "true"
+			Expect(k8sClient.Patch(ctx, &p, patch)).To(Succeed())
+		}
+
+		By("Reconciling: Suspending -> Suspended")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))).Should(BeFalse())
+	})
+
+	It("Running Workloads are restarted when their components are edited", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Editing the components")
+		aw := getAppWrapper(awName)
+		Expect(aw.Status.DeployedGeneration).Should(Equal(aw.Generation))
+		edited := pod(100, 0, false)
+		edited.PodSetInfos = aw.Spec.Components[0].PodSetInfos
+		aw.Spec.Components[0] = edited
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+
+		By("Reconciling: Running -> Suspending")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.UpdateCount).Should(Equal(int32(1)))
+		Expect(aw.Status.Retries).Should(Equal(int32(0)))
+		Expect(aw.Status.PreemptionCount).Should(Equal(int32(0)))
+
+		By("Reconciling: Suspending -> Suspended -> Resuming")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // reset the status of the renamed component
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+
+		By("Reconciling: Resuming -> Running with the edited components")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning))
+		Expect(aw.Status.DeployedGeneration).Should(Equal(aw.Generation))
+		Expect(getPods(aw)).Should(ContainElement(HaveField("Name", aw.Status.ComponentStatus[0].Name)))
+	})
+
+	It("Running Workloads are restarted when only their container images are edited", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Editing the image of a container")
+		aw := getAppWrapper(awName)
+		aw.Spec.Components[0].Template.Raw = bytes.ReplaceAll(aw.Spec.Components[0].Template.Raw, []byte("busybox:1.36"), []byte("busybox:1.37"))
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+
+		By("Reconciling: Running -> Suspending")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.UpdateCount).Should(Equal(int32(1)))
+
+		By("Reconciling: Suspending -> Suspended -> Resuming")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+
+		By("Reconciling: Resuming -> Running with the edited image")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning))
+		Expect(aw.Status.DeployedGeneration).Should(Equal(aw.Generation))
+		Expect(getPods(aw)).Should(ContainElement(And(HaveField("Name", aw.Status.ComponentStatus[0].Name),
+			HaveField("Spec.Containers", ContainElement(HaveField("Image", "quay.io/project-codeflare/busybox:1.37"))))))
+	})
+
+	It("Running Workloads deployed without a recorded generation are restarted when edited", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Clearing the deployed generation, as for an AppWrapper deployed by an earlier controller version")
+		aw := getAppWrapper(awName)
+		orig := copyForStatusPatch(aw)
+		aw.Status.DeployedGeneration = 0
+		Expect(k8sClient.Status().Patch(ctx, aw, client.MergeFrom(orig))).To(Succeed())
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning))
+		Expect(aw.Status.DeployedGeneration).Should(Equal(aw.Generation))
+
+		By("Editing the image of a container")
+		aw.Spec.Components[0].Template.Raw = bytes.ReplaceAll(aw.Spec.Components[0].Template.Raw, []byte("busybox:1.36"), []byte("busybox:1.37"))
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		Expect(aw.Status.UpdateCount).Should(Equal(int32(1)))
+	})
+
+	It("Users can request restarts", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Requesting a restart that does not count as a retry")
+		aw := getAppWrapper(awName)
+		aw.Annotations = map[string]string{
+			awv1beta2.RestartRequestAnnotation:       "first",
+			awv1beta2.RestartCountsAsRetryAnnotation: "false",
+		}
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+
+		By("Reconciling: Running -> Resetting")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResetting))
+		Expect(aw.Status.RestartRequest).Should(Equal("first"))
+		Expect(aw.Status.RestartCount).Should(Equal(int32(1)))
+		Expect(aw.Status.Retries).Should(Equal(int32(0)))
+
+		By("Reconciling: Resetting -> Resuming -> Running")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning), "an honoured request is not repeated")
+
+		By("Requesting a restart that counts as a retry")
+		aw = getAppWrapper(awName)
+		aw.Annotations[awv1beta2.RestartRequestAnnotation] = "second"
+		aw.Annotations[awv1beta2.RetryLimitAnnotation] = "1"
+		delete(aw.Annotations, awv1beta2.RestartCountsAsRetryAnnotation)
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResetting))
+		Expect(aw.Status.Retries).Should(Equal(int32(1)))
+		Expect(aw.Status.RestartCount).Should(Equal(int32(1)))
+
+		By("Reconciling: Resetting -> Resuming -> Running")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning))
+
+		By("Requesting a restart that counts as a retry when the RetryLimit is exhausted")
+		aw = getAppWrapper(awName)
+		aw.Annotations[awv1beta2.RestartRequestAnnotation] = "third"
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResetting), "a requested restart never fails the AppWrapper")
+		Expect(aw.Status.Retries).Should(Equal(int32(1)))
+		Expect(aw.Status.RestartCount).Should(Equal(int32(2)))
+	})
+
+	It("Resets that are not counted as retries begin a new attempt", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+		aw := getAppWrapper(awName)
+		Expect(aw.Status.Attempts).Should(Equal(int32(1)))
+
+		By("Resetting without incrementing any counter, as is done for Autopilot evacuations")
+		orig := copyForStatusPatch(aw)
+		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+			Type:   string(awv1beta2.Unhealthy),
+			Status: metav1.ConditionTrue,
+			Reason: "AutopilotNoExecute",
+		})
+		Expect(awReconciler.resetOrFail(ctx, orig, aw, false, 0)).To(Succeed())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperResetting))
+
+		By("Reconciling: Resetting -> Resuming")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		Expect(aw.Status.Retries).Should(Equal(int32(0)), "the reset was not counted as a retry")
+		Expect(aw.Status.Attempts).Should(Equal(int32(2)))
+
+		By("The Pods of the new attempt are labelled with a new attempt index")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		pods := getPods(aw)
+		Expect(pods).ShouldNot(BeEmpty())
+		for _, p := range pods {
+			Expect(p.Labels).Should(HaveKeyWithValue(awv1beta2.AppWrapperAttemptLabel, "2"))
+		}
+	})
+
+	It("Held AppWrappers are not resumed", func() {
+		aw := toAppWrapper(pod(100, 0, false))
+		aw.Spec.Suspend = true
+		aw.Annotations = map[string]string{awv1beta2.HoldAnnotation: "true"}
+		Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+		awName = types.NamespacedName{Name: aw.Name, Namespace: aw.Namespace}
+		awReconciler = &AppWrapperReconciler{
+			Client:   k8sIndexedClient,
+			Recorder: &events.FakeRecorder{},
+			Scheme:   k8sClient.Scheme(),
+			Config:   config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()),
+		}
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		wl := createWorkload(getAppWrapper(awName))
+		defer func() { Expect(k8sClient.Delete(ctx, wl)).To(Succeed()) }()
+
+		By("Kueue admits the held AppWrapper, whose Workload is deactivated to release its quota")
+		aw = getAppWrapper(awName)
+		aw.Spec.Suspend = false
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(workloadActive(wl)).Should(BeFalse())
+		Expect(wl.GetAnnotations()).Should(HaveKeyWithValue(awv1beta2.WorkloadDeactivatedAnnotation, heldReason))
+		aw = getAppWrapper(awName)
+		Expect(aw.Annotations).Should(HaveKeyWithValue(awv1beta2.WorkloadDeactivatedAnnotation, heldReason))
+
+		By("Releasing the hold reactivates the Workload")
+		aw.Annotations[awv1beta2.HoldAnnotation] = "false"
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		Expect(aw.Annotations).ShouldNot(HaveKey(awv1beta2.WorkloadDeactivatedAnnotation))
+		Expect(workloadActive(wl)).Should(BeTrue())
+	})
+
+	It("Running Workloads fail when their active deadline expires", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Imposing an active deadline that has already expired")
+		aw := getAppWrapper(awName)
+		aw.Annotations = map[string]string{awv1beta2.ActiveDeadlineDurationAnnotation: "1ns"}
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+
+		By("Reconciling: Running -> Failed")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperFailed))
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.Unhealthy)).Reason).Should(Equal("ActiveDeadlineExceeded"))
+		Expect(aw.Status.Retries).Should(Equal(int32(0)), "an expired deadline is not retried")
+	})
+
+	It("Running Workloads can be suspended when their active deadline expires", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		fullyRunning()
+
+		By("Imposing an active deadline that has already expired")
+		aw := getAppWrapper(awName)
+		wl := createWorkload(aw)
+		defer func() { Expect(k8sClient.Delete(ctx, wl)).To(Succeed()) }()
+		aw.Annotations = map[string]string{
+			awv1beta2.ActiveDeadlineDurationAnnotation:  "1ns",
+			awv1beta2.SuspendOnActiveDeadlineAnnotation: "true",
+		}
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+
+		By("Reconciling: Running -> Suspending -> Suspended")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspending))
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // initiate deletion
+		Expect(err).NotTo(HaveOccurred())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName}) // see deletion has completed
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(aw.Status.ActiveDuration).ShouldNot(BeNil())
+		Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.QuotaReserved)).Reason).Should(Equal("ActiveDeadlineExceeded"))
+
+		By("Remaining Suspended with a deactivated Workload until the deadline is extended")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(workloadActive(wl)).Should(BeFalse(), "the quota reserved by Kueue is released")
+		Expect(wl.GetAnnotations()).Should(HaveKeyWithValue(awv1beta2.WorkloadDeactivatedAnnotation, "ActiveDeadlineExceeded"))
+		aw = getAppWrapper(awName)
+		Expect(aw.Annotations).Should(HaveKeyWithValue(awv1beta2.WorkloadDeactivatedAnnotation, "ActiveDeadlineExceeded"))
+		aw.Annotations[awv1beta2.ActiveDeadlineDurationAnnotation] = "1h"
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+		Expect(workloadActive(wl)).Should(BeTrue())
+		Expect(wl.GetAnnotations()).ShouldNot(HaveKey(awv1beta2.WorkloadDeactivatedAnnotation))
 	})
 
 	It("A Pod Failure leads to a failed AppWrapper", func() {
@@ -359,11 +855,158 @@ var _ = Describe("AppWrapper Controller", func() {
 		By("Validate expected markers and Autopilot anti-affinities were injected")
 		for _, p := range pods {
 			Expect(p.Labels).Should(HaveKeyWithValue(awv1beta2.AppWrapperLabel, awName.Name))
+			Expect(p.Labels).Should(HaveKeyWithValue(awv1beta2.AppWrapperUIDLabel, string(aw.UID)))
+			Expect(p.Labels).Should(HaveKeyWithValue(awv1beta2.AppWrapperAttemptLabel, "1"))
 			validateMarkers(&p)
 			validateAutopilot(&p)
 		}
 	})
 
+	It("Component status is reset when the components of a Suspended AppWrapper are edited", func() {
+		aw := toAppWrapper(pod(100, 0, false), pod(100, 1, true))
+		aw.Spec.Suspend = true
+		Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+		awName = types.NamespacedName{Name: aw.Name, Namespace: aw.Namespace}
+		awReconciler = &AppWrapperReconciler{
+			Client:   k8sIndexedClient,
+			Recorder: &events.FakeRecorder{},
+			Scheme:   k8sClient.Scheme(),
+			Config:   config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()),
+		}
+
+		By("Reconciling: Empty -> Suspended")
+		_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(aw.Status.ComponentStatus).Should(HaveLen(2))
+
+		By("Reconciling an unchanged AppWrapper leaves its status alone")
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(getAppWrapper(awName).Status).Should(Equal(aw.Status))
+
+		By("Editing the components")
+		aw.Spec.Components = append(aw.Spec.Components, pod(200, 0, true))
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		_, err = awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperSuspended))
+		Expect(aw.Status.ComponentStatus).Should(HaveLen(3))
+		Expect(utils.ExpectedPodCount(aw)).Should(Equal(int32(3)))
+		Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+	})
+
+	It("Only Pods of the current AppWrapper incarnation are counted", func() {
+		advanceToResuming(pod(100, 0, false), pod(100, 1, true))
+		beginRunning()
+		aw := getAppWrapper(awName)
+
+		strayPod := func(labels map[string]string) *v1.Pod {
+			p := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: randName("stray"), Namespace: aw.Namespace, Labels: labels},
+				Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "busybox", Image: "quay.io/project-codeflare/busybox:1.36"}}},
+			}
+			Expect(k8sClient.Create(ctx, p)).To(Succeed())
+			p.Status.Phase = v1.PodFailed
+			Expect(k8sClient.Status().Update(ctx, p)).To(Succeed())
+			return p
+		}
+
+		By("A Pod of a previous incarnation is ignored")
+		stale := strayPod(map[string]string{awv1beta2.AppWrapperLabel: aw.Name, awv1beta2.AppWrapperUIDLabel: "previous-incarnation"})
+		podStatus, err := awReconciler.getPodStatus(ctx, aw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podStatus.failed).Should(Equal(int32(0)))
+		Expect(podStatus.pending).Should(Equal(int32(2)))
+
+		By("A lingering Pod of a previous attempt is ignored")
+		lingering := strayPod(map[string]string{awv1beta2.AppWrapperLabel: aw.Name, awv1beta2.AppWrapperUIDLabel: string(aw.UID), awv1beta2.AppWrapperAttemptLabel: "-1"})
+		podStatus, err = awReconciler.getPodStatus(ctx, aw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podStatus.failed).Should(Equal(int32(0)))
+		Expect(podStatus.pending).Should(Equal(int32(2)))
+
+		By("A Pod without a UID label that was created in a later second than the AppWrapper is counted")
+		legacy := strayPod(map[string]string{awv1beta2.AppWrapperLabel: aw.Name})
+		aw.CreationTimestamp = metav1.NewTime(legacy.CreationTimestamp.Add(-time.Second))
+		podStatus, err = awReconciler.getPodStatus(ctx, aw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podStatus.failed).Should(Equal(int32(1)))
+		Expect(podStatus.pending).Should(Equal(int32(2)))
+
+		By("A Pod without a UID label that was created in the same second as the AppWrapper is ignored")
+		aw.CreationTimestamp = legacy.CreationTimestamp
+		podStatus, err = awReconciler.getPodStatus(ctx, aw)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(podStatus.failed).Should(Equal(int32(0)), "it may belong to a previous incarnation with the same name")
+		Expect(podStatus.pending).Should(Equal(int32(2)))
+
+		By("Pods without a UID label are indexed by the name of their AppWrapper")
+		Expect(IndexPodByAppWrapper(legacy)).Should(Equal([]string{legacyPodIndexKey(aw.Name)}))
+		Expect(IndexPodByAppWrapper(stale)).Should(Equal([]string{"previous-incarnation"}))
+
+		Expect(k8sClient.Delete(ctx, stale, client.GracePeriodSeconds(0))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, lingering, client.GracePeriodSeconds(0))).To(Succeed())
+		Expect(k8sClient.Delete(ctx, legacy, client.GracePeriodSeconds(0))).To(Succeed())
+	})
+
+	Context("Impersonation of the AppWrapper's creator", func() {
+		impersonate := func(userInfo *authenticationv1.UserInfo) {
+			aw := getAppWrapper(awName)
+			if userInfo != nil {
+				userInfoBytes, err := json.Marshal(userInfo)
+				Expect(err).NotTo(HaveOccurred())
+				aw.Annotations = map[string]string{awv1beta2.AppWrapperUserInfoAnnotation: string(userInfoBytes)}
+				Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+			}
+			awReconciler.ImpersonationConfig = cfg
+		}
+
+		It("Components are created while impersonating a permitted creator", func() {
+			advanceToResuming(pod(100, 0, false))
+			impersonate(&authenticationv1.UserInfo{Username: "impersonated-admin", Groups: []string{"system:masters", "system:authenticated"}})
+			beginRunning()
+
+			By("Impersonating clients share one transport")
+			shared := awReconciler.impersonationTransport
+			Expect(shared).NotTo(BeNil())
+			_, err := awReconciler.componentCreator(getAppWrapper(awName))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(awReconciler.impersonationTransport).Should(BeIdenticalTo(shared))
+		})
+
+		It("Components are not created when the creator is not permitted to create them", func() {
+			advanceToResuming(pod(100, 0, false))
+			impersonate(&authenticationv1.UserInfo{Username: "unprivileged-user", Groups: []string{"system:authenticated"}})
+
+			By("Reconciling: Resuming -> Resuming")
+			_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+			Expect(err).NotTo(HaveOccurred())
+
+			aw := getAppWrapper(awName)
+			Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperResuming))
+			rd := meta.FindStatusCondition(aw.Status.ComponentStatus[0].Conditions, string(awv1beta2.ResourcesDeployed))
+			Expect(rd).NotTo(BeNil())
+			Expect(rd.Reason).Should(Equal("ComponentCreationErrored"))
+			Expect(getPods(aw)).Should(BeEmpty())
+		})
+
+		It("AppWrappers without a recorded creator fail", func() {
+			advanceToResuming(pod(100, 0, false))
+			impersonate(nil)
+
+			By("Reconciling: Resuming -> Failed")
+			_, err := awReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+			Expect(err).NotTo(HaveOccurred())
+
+			aw := getAppWrapper(awName)
+			Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperFailed))
+			Expect(meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.Unhealthy)).Message).Should(ContainSubstring(awv1beta2.AppWrapperUserInfoAnnotation))
+		})
+	})
+
 	It("Validating PodSet Injection invariants on complex pods", func() {
 		advanceToResuming(complexPodYaml(), complexPodYaml())
 		beginRunning()
@@ -404,23 +1047,24 @@ var _ = Describe("AppWrapper Annotations", func() {
 
 	BeforeEach(func() {
 		awReconciler = &AppWrapperReconciler{
-			Client:   k8sClient,
+			Client:   k8sIndexedClient,
 			Recorder: &events.FakeRecorder{},
 			Scheme:   k8sClient.Scheme(),
-			Config:   config.NewAppWrapperConfig(),
+			Config:   config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()),
 		}
 	})
 
 	It("Unannotated appwrappers use defaults", func() {
 		aw := &awv1beta2.AppWrapper{}
-		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.AdmissionGracePeriod))
-		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.WarmupGracePeriod))
-		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.FailureGracePeriod))
-		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.RetryLimit))
-		Expect(awReconciler.retryPauseDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.RetryPausePeriod))
-		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.ForcefulDeletionGracePeriod))
+		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.AdmissionGracePeriod))
+		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.WarmupGracePeriod))
+		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.FailureGracePeriod))
+		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.RetryLimit))
+		Expect(awReconciler.retryPauseDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.RetryPausePeriod))
+		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.ForcefulDeletionGracePeriod))
 		Expect(awReconciler.deletionOnFailureGraceDuration(ctx, aw)).Should(Equal(0 * time.Second))
-		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.SuccessTTL))
+		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.SuccessTTL))
+		Expect(awReconciler.activeDeadline(ctx, aw)).Should(Equal(0 * time.Second))
 	})
 
 	It("Valid annotations override defaults", func() {
@@ -465,19 +1109,19 @@ var _ = Describe("AppWrapper Annotations", func() {
 				},
 			},
 		}
-		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.AdmissionGracePeriod))
-		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.WarmupGracePeriod))
-		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.FailureGracePeriod))
-		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.RetryLimit))
-		Expect(awReconciler.retryPauseDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.RetryPausePeriod))
-		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.ForcefulDeletionGracePeriod))
+		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.AdmissionGracePeriod))
+		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.WarmupGracePeriod))
+		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.FailureGracePeriod))
+		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.RetryLimit))
+		Expect(awReconciler.retryPauseDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.RetryPausePeriod))
+		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.ForcefulDeletionGracePeriod))
 		Expect(awReconciler.deletionOnFailureGraceDuration(ctx, aw)).Should(Equal(0 * time.Second))
-		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.SuccessTTL))
+		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.SuccessTTL))
 	})
 
 	It("Out of bounds annotations are clipped", func() {
 		negative := -10 * time.Minute
-		tooLong := 2 * awReconciler.Config.FaultTolerance.GracePeriodMaximum
+		tooLong := 2 * awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum
 		aw := &awv1beta2.AppWrapper{
 			ObjectMeta: metav1.ObjectMeta{
 				Annotations: map[string]string{
@@ -487,17 +1131,60 @@ var _ = Describe("AppWrapper Annotations", func() {
 					awv1beta2.RetryPausePeriodDurationAnnotation:     negative.String(),
 					awv1beta2.ForcefulDeletionGracePeriodAnnotation:  tooLong.String(),
 					awv1beta2.DeletionOnFailureGracePeriodAnnotation: tooLong.String(),
-					awv1beta2.SuccessTTLAnnotation:                   (awReconciler.Config.FaultTolerance.SuccessTTL + 10*time.Second).String(),
+					awv1beta2.SuccessTTLAnnotation:                   (awReconciler.Config.Get().FaultTolerance.SuccessTTL + 10*time.Second).String(),
 				},
 			},
 		}
 		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(0 * time.Second))
-		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.GracePeriodMaximum))
-		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.GracePeriodMaximum))
+		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum))
+		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum))
 		Expect(awReconciler.retryPauseDuration(ctx, aw)).Should(Equal(0 * time.Second))
-		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.GracePeriodMaximum))
-		Expect(awReconciler.deletionOnFailureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.GracePeriodMaximum))
-		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.FaultTolerance.SuccessTTL))
+		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum))
+		Expect(awReconciler.deletionOnFailureGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum))
+		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.SuccessTTL))
+	})
+
+	It("Namespace annotations supply defaults and maximums", func() {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: randName("policy"),
+			Annotations: map[string]string{
+				awv1beta2.WarmupGracePeriodDurationAnnotation:                                      "20m",
+				awv1beta2.RetryLimitAnnotation:                                                     "8",
+				awv1beta2.RetryLimitAnnotation + awv1beta2.MaximumAnnotationSuffix:                 "10",
+				awv1beta2.FailureGracePeriodDurationAnnotation + awv1beta2.MaximumAnnotationSuffix: "30s",
+				awv1beta2.SuccessTTLAnnotation + awv1beta2.MaximumAnnotationSuffix:                 "1h",
+				awv1beta2.ForcefulDeletionGracePeriodAnnotation:                                    "48h",
+				awv1beta2.ActiveDeadlineDurationAnnotation + awv1beta2.MaximumAnnotationSuffix:     "72h",
+			},
+		}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+
+		aw := &awv1beta2.AppWrapper{ObjectMeta: metav1.ObjectMeta{Namespace: ns.Name}}
+		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(20 * time.Minute))
+		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(int32(8)))
+		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(30 * time.Second))
+		Expect(awReconciler.timeToLiveAfterSucceededDuration(ctx, aw)).Should(Equal(1 * time.Hour))
+		Expect(awReconciler.forcefulDeletionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.GracePeriodMaximum))
+		Expect(awReconciler.admissionGraceDuration(ctx, aw)).Should(Equal(awReconciler.Config.Get().FaultTolerance.AdmissionGracePeriod))
+		Expect(awReconciler.activeDeadline(ctx, aw)).Should(Equal(72*time.Hour), "a maximum replaces an unbounded active deadline")
+
+		By("AppWrapper annotations take precedence over namespace defaults but not namespace maximums")
+		aw.Annotations = map[string]string{
+			awv1beta2.WarmupGracePeriodDurationAnnotation:  "2m",
+			awv1beta2.RetryLimitAnnotation:                 "20",
+			awv1beta2.FailureGracePeriodDurationAnnotation: "5m",
+		}
+		Expect(awReconciler.warmupGraceDuration(ctx, aw)).Should(Equal(2 * time.Minute))
+		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(int32(10)))
+		Expect(awReconciler.failureGraceDuration(ctx, aw)).Should(Equal(30 * time.Second))
+
+		By("Namespace policies can be disabled")
+		noPolicies := *awReconciler.Config.Get()
+		noPolicies.NamespacePolicies = false
+		awReconciler.Config.Set(&noPolicies)
+		Expect(awReconciler.retryLimit(ctx, aw)).Should(Equal(int32(20)))
+
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
 	})
 
 	It("Parsing of terminal exits codes", func() {
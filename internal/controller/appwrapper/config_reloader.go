@@ -0,0 +1,105 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/project-codeflare/appwrapper/pkg/config"
+)
+
+// ConfigReloader watches the operator's ConfigMap and applies valid changes
+// to the hot-reloadable parts of the AppWrapperConfig without a restart.
+// The settings merged by config.MergeReloadable take effect for both the controllers
+// and the webhooks; the webhook's controllerName, managedByAllowList, controllerPreflight,
+// userRBACAdmissionCheck, controllerUsername and impersonateUser are read once by
+// SetupAppWrapperWebhook, so changes to them are only reported as requiring a restart.
+// Invalid updates are rejected and reported by an event on the ConfigMap.
+type ConfigReloader struct {
+	client.Client
+	Recorder  events.EventRecorder
+	ConfigMap types.NamespacedName
+	Config    *config.SharedAppWrapperConfig
+}
+
+// Reconcile validates the current contents of the ConfigMap and swaps in the resulting AppWrapperConfig
+func (r *ConfigReloader) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	configMap := &v1.ConfigMap{}
+	if err := r.Get(ctx, req.NamespacedName, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			log.FromContext(ctx).Info("Operator ConfigMap not found; keeping current configuration")
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	update := &config.OperatorConfig{AppWrapper: config.NewAppWrapperConfig()}
+	if err := config.ParseConfigMap(configMap, update); err != nil {
+		r.Recorder.Eventf(configMap, nil, v1.EventTypeWarning, "InvalidConfiguration", "Reload", "Configuration rejected: %v", err)
+		return ctrl.Result{}, nil
+	}
+	if update.AppWrapper == nil {
+		r.Recorder.Eventf(configMap, nil, v1.EventTypeWarning, "InvalidConfiguration", "Reload", "Configuration rejected: missing appwrapper section")
+		return ctrl.Result{}, nil
+	}
+	if err := config.ValidateAppWrapperConfig(update.AppWrapper); err != nil {
+		r.Recorder.Eventf(configMap, nil, v1.EventTypeWarning, "InvalidConfiguration", "Reload", "Configuration rejected: %v", err)
+		return ctrl.Result{}, nil
+	}
+
+	current := r.Config.Get()
+	merged, restartRequired := config.MergeReloadable(current, update.AppWrapper)
+	if len(restartRequired) > 0 {
+		r.Recorder.Eventf(configMap, nil, v1.EventTypeWarning, "RestartRequired", "Reload",
+			"Changes to %s take effect only after a restart", strings.Join(restartRequired, ", "))
+	}
+	if reflect.DeepEqual(current, merged) {
+		return ctrl.Result{}, nil
+	}
+
+	r.Config.Set(merged)
+	log.FromContext(ctx).Info("Reloaded configuration", "config", merged)
+	r.Recorder.Eventf(configMap, nil, v1.EventTypeNormal, "ConfigurationReloaded", "Reload", "Configuration reloaded")
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// The reloader runs in every replica, not just the leader, since webhooks also consume the configuration.
+func (r *ConfigReloader) SetupWithManager(mgr ctrl.Manager) error {
+	isConfigMap := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return obj.GetNamespace() == r.ConfigMap.Namespace && obj.GetName() == r.ConfigMap.Name
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.ConfigMap{}, builder.WithPredicates(isConfigMap, predicate.ResourceVersionChangedPredicate{})).
+		WithOptions(controller.Options{NeedLeaderElection: ptr.To(false)}).
+		Named("ConfigReloader").
+		Complete(r)
+}
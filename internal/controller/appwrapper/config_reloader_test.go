@@ -0,0 +1,107 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/project-codeflare/appwrapper/pkg/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Operator Config Reloading", func() {
+	var reloader *ConfigReloader
+	var recorder *events.FakeRecorder
+	var configMap *v1.ConfigMap
+
+	BeforeEach(func() {
+		configMap = &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: randName("operator-config"), Namespace: "default"},
+			Data:       map[string]string{"config.yaml": "appwrapper:\n  defaultQueueName: initial-queue\n"},
+		}
+		Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+
+		recorder = events.NewFakeRecorder(10)
+		reloader = &ConfigReloader{
+			Client:    k8sClient,
+			Recorder:  recorder,
+			ConfigMap: client.ObjectKeyFromObject(configMap),
+			Config:    config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, configMap)).To(Succeed())
+		reloader = nil
+	})
+
+	reconcileOnce := func() {
+		_, err := reloader.Reconcile(ctx, reconcile.Request{NamespacedName: reloader.ConfigMap})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	updateConfigMap := func(data string) {
+		Expect(k8sClient.Get(ctx, reloader.ConfigMap, configMap)).To(Succeed())
+		configMap.Data = map[string]string{"config.yaml": data}
+		Expect(k8sClient.Update(ctx, configMap)).To(Succeed())
+	}
+
+	It("Valid updates of hot-reloadable settings are applied", func() {
+		reconcileOnce()
+		Expect(reloader.Config.Get().DefaultQueueName).Should(Equal("initial-queue"))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("ConfigurationReloaded")))
+
+		updateConfigMap("appwrapper:\n  defaultQueueName: next-queue\n  faultTolerance:\n    retryLimit: 5\n")
+		reconcileOnce()
+		Expect(reloader.Config.Get().DefaultQueueName).Should(Equal("next-queue"))
+		Expect(reloader.Config.Get().FaultTolerance.RetryLimit).Should(Equal(int32(5)))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("ConfigurationReloaded")))
+	})
+
+	It("Invalid updates are rejected", func() {
+		reconcileOnce()
+		Expect(recorder.Events).Should(Receive(ContainSubstring("ConfigurationReloaded")))
+		before := reloader.Config.Get()
+
+		updateConfigMap("appwrapper:\n  defaultQueueName: bad-queue\n  faultTolerance:\n    gracePeriodCeiling: 1s\n    resetPause: 1m\n")
+		reconcileOnce()
+		Expect(reloader.Config.Get()).Should(BeIdenticalTo(before))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("InvalidConfiguration")))
+
+		updateConfigMap("not: [valid")
+		reconcileOnce()
+		Expect(reloader.Config.Get()).Should(BeIdenticalTo(before))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("InvalidConfiguration")))
+	})
+
+	It("Settings that require a restart are not applied", func() {
+		updateConfigMap("appwrapper:\n  controllerName: example.com/other\n  faultTolerance:\n    warmupGracePeriod: 10m\n")
+		reconcileOnce()
+		Expect(reloader.Config.Get().ControllerName).Should(Equal(config.NewAppWrapperConfig().ControllerName))
+		Expect(reloader.Config.Get().FaultTolerance.WarmupGracePeriod).Should(Equal(10 * time.Minute))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("RestartRequired")))
+		Expect(recorder.Events).Should(Receive(ContainSubstring("ConfigurationReloaded")))
+	})
+})
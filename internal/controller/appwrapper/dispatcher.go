@@ -0,0 +1,391 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/pkg/config"
+)
+
+const (
+	DispatcherFinalizer = "workload.codeflare.dev/dispatcher"
+	// DispatcherOriginLabel holds the UID of the AppWrapper a mirror AppWrapper was created for
+	DispatcherOriginLabel = "workload.codeflare.dev/dispatcher-origin"
+	// KubeconfigSecretKey is the key of the kubeconfig in a worker cluster Secret
+	KubeconfigSecretKey = "kubeconfig"
+)
+
+var errUnknownWorkerCluster = errors.New("unknown worker cluster")
+
+// DispatcherReconciler mirrors the AppWrappers managed by the dispatcher into worker clusters
+// and reflects the status of the mirror AppWrappers back into the management cluster.
+type DispatcherReconciler struct {
+	client.Client
+	APIReader client.Reader // reads kubeconfig Secrets without caching every Secret in the cluster
+	Recorder  events.EventRecorder
+	Scheme    *runtime.Scheme
+	Config    *config.SharedAppWrapperConfig
+
+	// NamespaceSelector restricts dispatching to AppWrappers whose namespace has matching labels (nil matches all)
+	NamespaceSelector labels.Selector
+
+	workersMutex sync.Mutex
+	workers      map[string]*workerCluster
+}
+
+// workerCluster caches the client of a worker cluster together with the Secret version it was built from
+type workerCluster struct {
+	client          client.Client
+	resourceVersion string
+	lastChecked     time.Time
+}
+
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+// Reconcile creates a mirror of an AppWrapper in its worker cluster, propagates suspension
+// and deletion to the mirror, and copies the status of the mirror back to the AppWrapper.
+func (r *DispatcherReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	aw := &awv1beta2.AppWrapper{}
+	if err := r.Get(ctx, req.NamespacedName, aw); err != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// stop reconciliation if not managed by the dispatcher
+	if ptr.Deref(aw.Spec.ManagedBy, "") != r.Config.Get().Dispatcher.ControllerName {
+		return ctrl.Result{}, nil
+	}
+
+	// stop reconciliation if the AppWrapper's namespace belongs to another shard
+	if selected, err := namespaceSelected(ctx, r.Client, r.NamespaceSelector, aw.Namespace); err != nil || !selected {
+		return ctrl.Result{}, err
+	}
+
+	// handle deletion first
+	if !aw.DeletionTimestamp.IsZero() {
+		if controllerutil.ContainsFinalizer(aw, DispatcherFinalizer) {
+			if aw.Status.DispatchedTo != "" {
+				deleted, err := r.deleteMirror(ctx, aw)
+				if err != nil {
+					return ctrl.Result{}, err
+				}
+				if !deleted {
+					return ctrl.Result{RequeueAfter: r.Config.Get().Dispatcher.SyncPeriod}, nil
+				}
+			}
+			if controllerutil.RemoveFinalizer(aw, DispatcherFinalizer) {
+				if err := r.Update(ctx, aw); err != nil {
+					return ctrl.Result{}, err
+				}
+				log.FromContext(ctx).Info("Deleted")
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// add finalizer so the mirror is deleted with the AppWrapper
+	if controllerutil.AddFinalizer(aw, DispatcherFinalizer) {
+		return ctrl.Result{}, r.Update(ctx, aw)
+	}
+
+	orig := copyForStatusPatch(aw)
+
+	// choose a worker cluster and record the choice before creating the mirror
+	if aw.Status.DispatchedTo == "" {
+		if aw.Spec.Suspend {
+			if aw.Status.Phase == awv1beta2.AppWrapperEmpty {
+				aw.Status.Phase = awv1beta2.AppWrapperSuspended
+				return ctrl.Result{}, r.Status().Patch(ctx, aw, client.MergeFrom(orig))
+			}
+			return ctrl.Result{}, nil
+		}
+		target, err := r.selectWorkerCluster(aw)
+		if err != nil {
+			r.Recorder.Eventf(aw, nil, v1.EventTypeWarning, "DispatchFailed", "Dispatch", "%v", err)
+			return ctrl.Result{}, nil
+		}
+		aw.Status.DispatchedTo = target
+		return ctrl.Result{}, r.Status().Patch(ctx, aw, client.MergeFrom(orig))
+	}
+
+	worker, err := r.workerClient(ctx, aw.Status.DispatchedTo)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	mirror := &awv1beta2.AppWrapper{}
+	if err := worker.Get(ctx, client.ObjectKeyFromObject(aw), mirror); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if aw.Spec.Suspend {
+			if aw.Status.Phase != awv1beta2.AppWrapperSuspended {
+				aw.Status.Phase = awv1beta2.AppWrapperSuspended
+				return ctrl.Result{}, r.Status().Patch(ctx, aw, client.MergeFrom(orig))
+			}
+			return ctrl.Result{}, nil
+		}
+		if isTerminalPhase(aw.Status.Phase) {
+			return ctrl.Result{}, nil
+		}
+		if err := worker.Create(ctx, newMirror(aw)); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "Dispatched", "Dispatch", "Created mirror in worker cluster %v", aw.Status.DispatchedTo)
+		if aw.Status.DispatchedSuspend {
+			aw.Status.DispatchedSuspend = false // the mirror is created unsuspended
+			if err := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{RequeueAfter: r.Config.Get().Dispatcher.SyncPeriod}, nil
+	}
+
+	if mirror.Labels[DispatcherOriginLabel] != string(aw.UID) {
+		r.Recorder.Eventf(aw, nil, v1.EventTypeWarning, "DispatchConflict", "Dispatch",
+			"An AppWrapper %v that is not a mirror of this AppWrapper exists in worker cluster %v", req.NamespacedName, aw.Status.DispatchedTo)
+		return ctrl.Result{RequeueAfter: r.Config.Get().Dispatcher.SyncPeriod}, nil
+	}
+
+	// propagate changes of suspension in the management cluster; the mirror may also be suspended
+	// by the queueing system of the worker cluster and that suspension must not be undone
+	if aw.Spec.Suspend != aw.Status.DispatchedSuspend {
+		if mirror.Spec.Suspend != aw.Spec.Suspend {
+			mirrorOrig := mirror.DeepCopy()
+			mirror.Spec.Suspend = aw.Spec.Suspend
+			if err := worker.Patch(ctx, mirror, client.MergeFrom(mirrorOrig)); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		aw.Status.DispatchedSuspend = aw.Spec.Suspend
+	}
+
+	// propagate edits of the components; the worker cluster validates them against the state of the mirror
+	mirrorOrig := mirror.DeepCopy()
+	if syncComponents(aw, mirror) {
+		if err := worker.Patch(ctx, mirror, client.MergeFrom(mirrorOrig)); err != nil {
+			if !apierrors.IsInvalid(err) && !apierrors.IsForbidden(err) {
+				return ctrl.Result{}, err
+			}
+			r.Recorder.Eventf(aw, nil, v1.EventTypeWarning, "SyncFailed", "Dispatch",
+				"Worker cluster %v rejected the edited components: %v", aw.Status.DispatchedTo, err)
+		}
+	}
+
+	// reflect the status of the mirror
+	aw.Status.Phase = mirror.Status.Phase
+	aw.Status.Attempts = mirror.Status.Attempts
+	aw.Status.Retries = mirror.Status.Retries
+	aw.Status.PreemptionCount = mirror.Status.PreemptionCount
+	aw.Status.SuspensionCount = mirror.Status.SuspensionCount
+	aw.Status.SuspendedDuration = mirror.Status.SuspendedDuration
+	aw.Status.ActiveDuration = mirror.Status.ActiveDuration
+	aw.Status.UpdateCount = mirror.Status.UpdateCount
+	aw.Status.RestartCount = mirror.Status.RestartCount
+	aw.Status.Conditions = mirror.Status.Conditions
+	aw.Status.ComponentStatus = mirror.Status.ComponentStatus
+	if !equality.Semantic.DeepEqual(orig.Status, aw.Status) {
+		if err := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if isTerminalPhase(aw.Status.Phase) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: r.Config.Get().Dispatcher.SyncPeriod}, nil
+}
+
+// selectWorkerCluster returns the worker cluster requested by the WorkerClusterAnnotation
+// or the first configured worker cluster if the AppWrapper does not request one
+func (r *DispatcherReconciler) selectWorkerCluster(aw *awv1beta2.AppWrapper) (string, error) {
+	workerClusters := r.Config.Get().Dispatcher.WorkerClusters
+	requested, ok := aw.Annotations[awv1beta2.WorkerClusterAnnotation]
+	if !ok {
+		return workerClusters[0].Name, nil
+	}
+	for _, wc := range workerClusters {
+		if wc.Name == requested {
+			return requested, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %v", errUnknownWorkerCluster, requested)
+}
+
+// workerClient returns a client for the named worker cluster.
+// The kubeconfig Secret is re-read at most once per SyncPeriod to pick up rotated credentials.
+func (r *DispatcherReconciler) workerClient(ctx context.Context, name string) (client.Client, error) {
+	var wc *config.WorkerClusterConfig
+	dispatcher := r.Config.Get().Dispatcher
+	for idx := range dispatcher.WorkerClusters {
+		if dispatcher.WorkerClusters[idx].Name == name {
+			wc = &dispatcher.WorkerClusters[idx]
+		}
+	}
+	if wc == nil {
+		return nil, fmt.Errorf("%w: %v", errUnknownWorkerCluster, name)
+	}
+
+	r.workersMutex.Lock() // BEGIN CRITICAL SECTION
+	defer r.workersMutex.Unlock()
+	cached, ok := r.workers[name]
+	if ok && time.Since(cached.lastChecked) < dispatcher.SyncPeriod {
+		return cached.client, nil
+	}
+
+	secret := &v1.Secret{}
+	if err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: dispatcher.SecretNamespace, Name: wc.KubeconfigSecret}, secret); err != nil {
+		return nil, fmt.Errorf("reading kubeconfig of worker cluster %v: %w", name, err)
+	}
+	if ok && cached.resourceVersion == secret.ResourceVersion {
+		cached.lastChecked = time.Now()
+		return cached.client, nil
+	}
+	kubeconfig, found := secret.Data[KubeconfigSecretKey]
+	if !found {
+		return nil, fmt.Errorf("secret %v/%v has no %v key", secret.Namespace, secret.Name, KubeconfigSecretKey)
+	}
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubeconfig of worker cluster %v: %w", name, err)
+	}
+	workerClient, err := client.New(restConfig, client.Options{Scheme: r.Scheme})
+	if err != nil {
+		return nil, err
+	}
+	if r.workers == nil {
+		r.workers = map[string]*workerCluster{}
+	}
+	r.workers[name] = &workerCluster{client: workerClient, resourceVersion: secret.ResourceVersion, lastChecked: time.Now()}
+	return workerClient, nil
+}
+
+// deleteMirror initiates the deletion of the mirror of aw and returns true once the mirror is gone
+func (r *DispatcherReconciler) deleteMirror(ctx context.Context, aw *awv1beta2.AppWrapper) (bool, error) {
+	worker, err := r.workerClient(ctx, aw.Status.DispatchedTo)
+	if err != nil {
+		if errors.Is(err, errUnknownWorkerCluster) {
+			// The worker cluster was removed from the configuration; nothing left to clean up
+			log.FromContext(ctx).Info("Skipping deletion of mirror", "reason", err)
+			return true, nil
+		}
+		return false, err
+	}
+	mirror := &awv1beta2.AppWrapper{}
+	if err := worker.Get(ctx, client.ObjectKeyFromObject(aw), mirror); err != nil {
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	if mirror.Labels[DispatcherOriginLabel] != string(aw.UID) {
+		return true, nil // not our mirror
+	}
+	if mirror.DeletionTimestamp.IsZero() {
+		if err := worker.Delete(ctx, mirror, client.Preconditions{UID: &mirror.UID}, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// newMirror constructs the AppWrapper that is created in the worker cluster for aw.
+// The mirror is managed by the worker cluster's default controller and is admitted
+// by the worker cluster's own queueing system, so PodSetInfos are not carried over.
+func newMirror(aw *awv1beta2.AppWrapper) *awv1beta2.AppWrapper {
+	mirror := &awv1beta2.AppWrapper{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        aw.Name,
+			Namespace:   aw.Namespace,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+	}
+	for k, v := range aw.Labels {
+		mirror.Labels[k] = v
+	}
+	mirror.Labels[DispatcherOriginLabel] = string(aw.UID)
+	for k, v := range aw.Annotations {
+		mirror.Annotations[k] = v
+	}
+	for _, component := range aw.Spec.Components {
+		mirror.Spec.Components = append(mirror.Spec.Components, awv1beta2.AppWrapperComponent{
+			Annotations:     component.Annotations,
+			DeclaredPodSets: component.DeclaredPodSets,
+			Template:        *component.Template.DeepCopy(),
+		})
+	}
+	return mirror
+}
+
+// syncComponents copies the components of aw to its mirror and returns true if the mirror was changed.
+// The PodSetInfos set on the mirror by the worker cluster's queueing system are retained.
+func syncComponents(aw *awv1beta2.AppWrapper, mirror *awv1beta2.AppWrapper) bool {
+	components := newMirror(aw).Spec.Components
+	if len(components) == len(mirror.Spec.Components) {
+		for idx := range components {
+			components[idx].PodSetInfos = mirror.Spec.Components[idx].PodSetInfos
+		}
+	}
+	if equality.Semantic.DeepEqual(components, mirror.Spec.Components) {
+		return false
+	}
+	mirror.Spec.Components = components
+	return true
+}
+
+func isTerminalPhase(phase awv1beta2.AppWrapperPhase) bool {
+	return phase == awv1beta2.AppWrapperSucceeded || phase == awv1beta2.AppWrapperFailed
+}
+
+// SetupWithManager sets up the dispatcher with the Manager.
+func (r *DispatcherReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	dispatched := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		aw, ok := obj.(*awv1beta2.AppWrapper)
+		return ok && ptr.Deref(aw.Spec.ManagedBy, "") == r.Config.Get().Dispatcher.ControllerName
+	})
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&awv1beta2.AppWrapper{}, builder.WithPredicates(dispatched))
+	if r.NamespaceSelector != nil {
+		b = b.Watches(&v1.Namespace{}, handler.EnqueueRequestsFromMapFunc(appWrappersInNamespace(r.Client)),
+			builder.WithPredicates(predicate.LabelChangedPredicate{}))
+	}
+	return b.Named("appwrapper-dispatcher").Complete(r)
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"bytes"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/pkg/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppWrapper Dispatcher", func() {
+	var dispatcher *DispatcherReconciler
+	var secret *v1.Secret
+
+	BeforeEach(func() {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: randName("worker-kubeconfig"), Namespace: "default"},
+			Data:       map[string][]byte{KubeconfigSecretKey: workerKubeconfig},
+		}
+		Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+		awConfig := config.NewAppWrapperConfig()
+		awConfig.Dispatcher.Enabled = true
+		awConfig.Dispatcher.SecretNamespace = secret.Namespace
+		awConfig.Dispatcher.WorkerClusters = []config.WorkerClusterConfig{{Name: "worker", KubeconfigSecret: secret.Name}}
+		Expect(config.ValidateAppWrapperConfig(awConfig)).To(Succeed())
+
+		dispatcher = &DispatcherReconciler{
+			Client:    k8sClient,
+			APIReader: k8sClient,
+			Recorder:  &events.FakeRecorder{},
+			Scheme:    k8sClient.Scheme(),
+			Config:    config.NewSharedAppWrapperConfig(awConfig),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(k8sClient.Delete(ctx, secret)).To(Succeed())
+		dispatcher = nil
+	})
+
+	reconcileOnce := func(awName types.NamespacedName) {
+		_, err := dispatcher.Reconcile(ctx, reconcile.Request{NamespacedName: awName})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("AppWrappers are mirrored to, synchronized with, and deleted from the worker cluster", func() {
+		aw := toAppWrapper(pod(100, 0, true))
+		aw.Spec.ManagedBy = ptr.To(config.DispatcherControllerName)
+		Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+		awName := client.ObjectKeyFromObject(aw)
+
+		By("Reconciling: add finalizer, select worker cluster, create mirror")
+		reconcileOnce(awName)
+		aw = getAppWrapper(awName)
+		Expect(controllerutil.ContainsFinalizer(aw, DispatcherFinalizer)).Should(BeTrue())
+		reconcileOnce(awName)
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.DispatchedTo).Should(Equal("worker"))
+		reconcileOnce(awName)
+
+		mirror := &awv1beta2.AppWrapper{}
+		Expect(workerClient.Get(ctx, awName, mirror)).To(Succeed())
+		Expect(mirror.Labels).Should(HaveKeyWithValue(DispatcherOriginLabel, string(aw.UID)))
+		Expect(mirror.Spec.ManagedBy).Should(BeNil())
+		Expect(mirror.Spec.Components).Should(HaveLen(1))
+
+		By("Status of the mirror is reflected in the management cluster")
+		mirror.Status.Phase = awv1beta2.AppWrapperRunning
+		mirror.Status.Retries = 1
+		meta.SetStatusCondition(&mirror.Status.Conditions, metav1.Condition{
+			Type:   string(awv1beta2.ResourcesDeployed),
+			Status: metav1.ConditionTrue,
+			Reason: string(awv1beta2.AppWrapperRunning),
+		})
+		mirror.Status.ComponentStatus = []awv1beta2.AppWrapperComponentStatus{{Name: "mirrored", Kind: "Pod", APIVersion: "v1", PodSets: []awv1beta2.AppWrapperPodSet{}}}
+		Expect(workerClient.Status().Update(ctx, mirror)).To(Succeed())
+		reconcileOnce(awName)
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.Phase).Should(Equal(awv1beta2.AppWrapperRunning))
+		Expect(aw.Status.Retries).Should(Equal(int32(1)))
+		Expect(meta.IsStatusConditionTrue(aw.Status.Conditions, string(awv1beta2.ResourcesDeployed))).Should(BeTrue())
+		Expect(aw.Status.ComponentStatus).Should(HaveLen(1))
+		Expect(aw.Status.ComponentStatus[0].Name).Should(Equal("mirrored"))
+		Expect(aw.Status.DispatchedTo).Should(Equal("worker"))
+
+		By("Suspension of the mirror by the worker cluster is not undone")
+		Expect(workerClient.Get(ctx, awName, mirror)).To(Succeed())
+		mirror.Spec.Suspend = true
+		Expect(workerClient.Update(ctx, mirror)).To(Succeed())
+		reconcileOnce(awName)
+		Expect(workerClient.Get(ctx, awName, mirror)).To(Succeed())
+		Expect(mirror.Spec.Suspend).Should(BeTrue())
+		mirror.Spec.Suspend = false
+		Expect(workerClient.Update(ctx, mirror)).To(Succeed())
+
+		By("Edits of the components are propagated to the mirror")
+		aw = getAppWrapper(awName)
+		aw.Spec.Components[0].Template.Raw = bytes.ReplaceAll(aw.Spec.Components[0].Template.Raw, []byte("busybox:1.36"), []byte("busybox:1.37"))
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		reconcileOnce(awName)
+		Expect(workerClient.Get(ctx, awName, mirror)).To(Succeed())
+		Expect(string(mirror.Spec.Components[0].Template.Raw)).Should(ContainSubstring("busybox:1.37"))
+
+		By("Suspension is propagated to the mirror")
+		aw = getAppWrapper(awName)
+		aw.Spec.Suspend = true
+		Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+		reconcileOnce(awName)
+		Expect(workerClient.Get(ctx, awName, mirror)).To(Succeed())
+		Expect(mirror.Spec.Suspend).Should(BeTrue())
+		Expect(getAppWrapper(awName).Status.DispatchedSuspend).Should(BeTrue())
+
+		By("Deletion is propagated to the mirror")
+		Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+		reconcileOnce(awName) // initiate deletion of the mirror
+		Expect(apierrors.IsNotFound(workerClient.Get(ctx, awName, mirror))).Should(BeTrue())
+		reconcileOnce(awName) // see deletion has completed
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, awName, aw))).Should(BeTrue())
+	})
+
+	It("AppWrappers requesting an unknown worker cluster are not dispatched", func() {
+		aw := toAppWrapper(pod(100, 0, true))
+		aw.Spec.ManagedBy = ptr.To(config.DispatcherControllerName)
+		aw.Annotations = map[string]string{awv1beta2.WorkerClusterAnnotation: "no-such-cluster"}
+		Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+		awName := client.ObjectKeyFromObject(aw)
+
+		reconcileOnce(awName)
+		reconcileOnce(awName)
+		aw = getAppWrapper(awName)
+		Expect(aw.Status.DispatchedTo).Should(BeEmpty())
+		Expect(apierrors.IsNotFound(workerClient.Get(ctx, awName, &awv1beta2.AppWrapper{}))).Should(BeTrue())
+
+		Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+		reconcileOnce(awName)
+		Expect(apierrors.IsNotFound(k8sClient.Get(ctx, awName, aw))).Should(BeTrue())
+	})
+
+	It("AppWrappers managed by other controllers are ignored", func() {
+		aw := toAppWrapper(pod(100, 0, true))
+		Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+		awName := client.ObjectKeyFromObject(aw)
+
+		reconcileOnce(awName)
+		aw = getAppWrapper(awName)
+		Expect(controllerutil.ContainsFinalizer(aw, DispatcherFinalizer)).Should(BeFalse())
+		Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+	})
+})
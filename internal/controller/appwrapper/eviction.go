@@ -0,0 +1,222 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+)
+
+// rbacs required to read the reason Kueue evicted the Workload of an AppWrapper and to deactivate the Workload
+//+kubebuilder:rbac:groups=kueue.x-k8s.io,resources=workloads,verbs=list;patch
+
+const (
+	// kueueJobUIDLabel is the label Kueue adds to a Workload to identify the job it represents
+	kueueJobUIDLabel = "kueue.x-k8s.io/job-uid"
+	// kueueEvictedByPreemption is the reason of the Evicted condition of a Workload that Kueue preempted
+	kueueEvictedByPreemption = "Preempted"
+	// kueueEvictedByPodsReadyTimeout is the reason of the Evicted condition of a Workload whose Pods did not become ready in time
+	kueueEvictedByPodsReadyTimeout = "PodsReadyTimeout"
+)
+
+var kueueWorkloadListGVK = schema.GroupVersionKind{Group: "kueue.x-k8s.io", Version: "v1beta1", Kind: "WorkloadList"}
+
+// kueueEviction returns the reason and message of the Evicted condition of the Kueue Workload that represents aw.
+// The reason is empty if Kueue is not installed, aw has no Workload, or the Workload has not been evicted.
+func (r *AppWrapperReconciler) kueueEviction(ctx context.Context, aw *awv1beta2.AppWrapper) (string, string) {
+	workloads, err := r.kueueWorkloads(ctx, aw)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Workload list error")
+		return "", ""
+	}
+	for _, wl := range workloads {
+		conditions, _, _ := unstructured.NestedSlice(wl.Object, "status", "conditions")
+		for _, c := range conditions {
+			if cond, ok := c.(map[string]interface{}); ok && cond["type"] == "Evicted" && cond["status"] == string(metav1.ConditionTrue) {
+				reason, _ := cond["reason"].(string)
+				message, _ := cond["message"].(string)
+				return reason, message
+			}
+		}
+	}
+	return "", ""
+}
+
+// kueueWorkloads returns the Kueue Workloads that represent aw; there are none if Kueue is not installed
+func (r *AppWrapperReconciler) kueueWorkloads(ctx context.Context, aw *awv1beta2.AppWrapper) ([]unstructured.Unstructured, error) {
+	workloads := &unstructured.UnstructuredList{}
+	workloads.SetGroupVersionKind(kueueWorkloadListGVK)
+	if err := r.List(ctx, workloads, client.InNamespace(aw.Namespace), client.MatchingLabels{kueueJobUIDLabel: string(aw.UID)}); err != nil {
+		if meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return workloads.Items, nil
+}
+
+// syncWorkloadActive deactivates the Kueue Workloads of aw if inactiveReason is not empty and otherwise reactivates
+// the Workloads it previously deactivated. Kueue releases the quota of an inactive Workload by suspending aw.
+// Workloads deactivated by someone else are never reactivated. The Workloads are only listed when inactiveReason
+// differs from the WorkloadDeactivatedAnnotation of aw, which records the outcome of the last successful sync.
+// Errors are logged rather than returned so that they cannot keep aw from resuming.
+func (r *AppWrapperReconciler) syncWorkloadActive(ctx context.Context, aw *awv1beta2.AppWrapper, inactiveReason string) {
+	if aw.Annotations[awv1beta2.WorkloadDeactivatedAnnotation] == inactiveReason {
+		return // nothing has changed since the last sync
+	}
+	found, err := r.patchWorkloadsActive(ctx, aw, inactiveReason)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Workload activation error", "inactiveReason", inactiveReason)
+		return
+	}
+	if inactiveReason != "" && found == 0 {
+		return // Kueue has not created the Workload yet; try again on the next reconcile
+	}
+
+	// patch a copy to avoid overwriting pending changes to the status of aw with the server's response
+	modified := aw.DeepCopy()
+	patch := client.MergeFrom(aw)
+	if inactiveReason != "" {
+		metav1.SetMetaDataAnnotation(&modified.ObjectMeta, awv1beta2.WorkloadDeactivatedAnnotation, inactiveReason)
+	} else {
+		delete(modified.Annotations, awv1beta2.WorkloadDeactivatedAnnotation)
+	}
+	if err := r.Patch(ctx, modified, patch); err != nil {
+		log.FromContext(ctx).Error(err, "AppWrapper annotation error", "inactiveReason", inactiveReason)
+		return
+	}
+	aw.Annotations = modified.Annotations
+	aw.ResourceVersion = modified.ResourceVersion
+}
+
+// patchWorkloadsActive implements syncWorkloadActive for the Workloads of aw and returns the number of Workloads found
+func (r *AppWrapperReconciler) patchWorkloadsActive(ctx context.Context, aw *awv1beta2.AppWrapper, inactiveReason string) (int, error) {
+	workloads, err := r.kueueWorkloads(ctx, aw)
+	if err != nil {
+		return 0, err
+	}
+	for idx := range workloads {
+		wl := &workloads[idx]
+		active, found, _ := unstructured.NestedBool(wl.Object, "spec", "active")
+		active = active || !found
+		_, deactivatedByUs := wl.GetAnnotations()[awv1beta2.WorkloadDeactivatedAnnotation]
+		if deactivate := inactiveReason != ""; active != deactivate || (!deactivate && !deactivatedByUs) {
+			continue // nothing to change
+		}
+		patch := client.MergeFrom(wl.DeepCopy())
+		annotations := wl.GetAnnotations()
+		if inactiveReason != "" {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[awv1beta2.WorkloadDeactivatedAnnotation] = inactiveReason
+		} else {
+			delete(annotations, awv1beta2.WorkloadDeactivatedAnnotation)
+		}
+		wl.SetAnnotations(annotations)
+		if err := unstructured.SetNestedField(wl.Object, inactiveReason == "", "spec", "active"); err != nil {
+			return 0, err
+		}
+		if err := r.Patch(ctx, wl, patch); err != nil {
+			return 0, err
+		}
+		if inactiveReason != "" {
+			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "WorkloadDeactivated", string(awv1beta2.AppWrapperSuspended), "Deactivated Workload %v to release its quota: %v", wl.GetName(), inactiveReason)
+		} else {
+			r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "WorkloadActivated", string(awv1beta2.AppWrapperSuspended), "Reactivated Workload %v", wl.GetName())
+		}
+	}
+	return len(workloads), nil
+}
+
+// recordSuspension classifies and records the external suspension of aw while in phase.
+// Preemptions by Kueue increment PreemptionCount. Evictions by Kueue because the workload's Pods did not become ready
+// are failures and increment Retries (unless the failure was already counted by entering the Resetting phase).
+// All other suspensions increment SuspensionCount. The Preempted condition records the cause of the suspension.
+func (r *AppWrapperReconciler) recordSuspension(ctx context.Context, aw *awv1beta2.AppWrapper, phase awv1beta2.AppWrapperPhase) {
+	reason, message := r.kueueEviction(ctx, aw)
+	if reason == "" {
+		reason, message = string(awv1beta2.AppWrapperSuspended), "Suspend is true"
+	}
+	preempted := metav1.ConditionFalse
+	switch reason {
+	case kueueEvictedByPreemption:
+		preempted = metav1.ConditionTrue
+		aw.Status.PreemptionCount += 1
+		r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "Preempted", string(awv1beta2.AppWrapperSuspending), "Preempted while in the %v phase: %v", phase, message)
+	case kueueEvictedByPodsReadyTimeout:
+		if phase != awv1beta2.AppWrapperResetting {
+			aw.Status.Retries += 1
+		}
+		r.Recorder.Eventf(aw, nil, v1.EventTypeWarning, "EvictedUnhealthy", string(awv1beta2.AppWrapperSuspending), "Evicted while in the %v phase: %v", phase, message)
+	default:
+		aw.Status.SuspensionCount += 1
+		r.Recorder.Eventf(aw, nil, v1.EventTypeNormal, "ExternallySuspended", string(awv1beta2.AppWrapperSuspending), "Externally suspended while in the %v phase", phase)
+	}
+	meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+		Type:    string(awv1beta2.Preempted),
+		Status:  preempted,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// suspensionCause returns the reason and message recorded by recordSuspension for the current suspension of aw.
+// It returns empty strings if aw was not externally suspended (for example, if it is being restarted to deploy edits).
+func suspensionCause(aw *awv1beta2.AppWrapper) (string, string) {
+	if cond := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.Preempted)); cond != nil && cond.Reason != string(awv1beta2.AppWrapperResuming) {
+		return cond.Reason, cond.Message
+	}
+	return "", ""
+}
+
+// evictedAsUnhealthy returns true if aw is being suspended because Kueue evicted it when its Pods did not become ready
+func evictedAsUnhealthy(aw *awv1beta2.AppWrapper) bool {
+	reason, _ := suspensionCause(aw)
+	return reason == kueueEvictedByPodsReadyTimeout
+}
+
+// recordResumption adds the time aw spent suspended since its last external suspension to its SuspendedDuration
+// and resets its Preempted condition
+func recordResumption(aw *awv1beta2.AppWrapper) {
+	if reason, _ := suspensionCause(aw); reason == "" {
+		return
+	}
+	if qr := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.QuotaReserved)); qr != nil && qr.Status == metav1.ConditionFalse {
+		suspended := time.Since(qr.LastTransitionTime.Time)
+		if aw.Status.SuspendedDuration != nil {
+			suspended += aw.Status.SuspendedDuration.Duration
+		}
+		aw.Status.SuspendedDuration = &metav1.Duration{Duration: suspended.Round(time.Second)}
+	}
+	meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+		Type:    string(awv1beta2.Preempted),
+		Status:  metav1.ConditionFalse,
+		Reason:  string(awv1beta2.AppWrapperResuming),
+		Message: "Suspend is false",
+	})
+}
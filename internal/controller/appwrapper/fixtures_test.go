@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
@@ -68,6 +69,41 @@ func getNode(name string) *v1.Node {
 	return node
 }
 
+// createWorkload creates a Kueue Workload that represents aw
+func createWorkload(aw *awv1beta2.AppWrapper) *unstructured.Unstructured {
+	wl := &unstructured.Unstructured{}
+	wl.SetAPIVersion("kueue.x-k8s.io/v1beta1")
+	wl.SetKind("Workload")
+	wl.SetNamespace(aw.Namespace)
+	wl.SetName(randName("workload"))
+	wl.SetLabels(map[string]string{kueueJobUIDLabel: string(aw.UID)})
+	Expect(unstructured.SetNestedField(wl.Object, true, "spec", "active")).To(Succeed())
+	Expect(k8sClient.Create(ctx, wl)).To(Succeed())
+	return wl
+}
+
+// evictWorkload sets the Evicted condition of the Workload wl as Kueue does when it evicts wl for reason
+func evictWorkload(wl *unstructured.Unstructured, reason string) {
+	Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), wl)).To(Succeed())
+	conditions := []interface{}{map[string]interface{}{
+		"type":               "Evicted",
+		"status":             string(metav1.ConditionTrue),
+		"reason":             reason,
+		"message":            "Evicted by test",
+		"lastTransitionTime": metav1.Now().UTC().Format(time.RFC3339),
+	}}
+	Expect(unstructured.SetNestedSlice(wl.Object, conditions, "status", "conditions")).To(Succeed())
+	Expect(k8sClient.Status().Update(ctx, wl)).To(Succeed())
+}
+
+// workloadActive returns the value of spec.active of the Workload wl
+func workloadActive(wl *unstructured.Unstructured) bool {
+	Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(wl), wl)).To(Succeed())
+	active, found, err := unstructured.NestedBool(wl.Object, "spec", "active")
+	Expect(err).NotTo(HaveOccurred())
+	return active || !found
+}
+
 func getPods(aw *awv1beta2.AppWrapper) []v1.Pod {
 	result := []v1.Pod{}
 	podList := &v1.PodList{}
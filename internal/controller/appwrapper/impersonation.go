@@ -0,0 +1,75 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+)
+
+// componentCreator returns the client used to create the components of aw.
+// If impersonation is enabled, it is a client that impersonates the user who created aw
+// so that the user's RBAC permissions are enforced every time the components are created.
+// The impersonating clients share one transport, and with it their connections to the API server;
+// the impersonation headers are added to each request by a per-client round tripper.
+func (r *AppWrapperReconciler) componentCreator(aw *awv1beta2.AppWrapper) (client.Client, error) {
+	if r.ImpersonationConfig == nil {
+		return r.Client, nil
+	}
+	recorded, ok := aw.Annotations[awv1beta2.AppWrapperUserInfoAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("cannot impersonate the creator of the AppWrapper: annotation %v is missing", awv1beta2.AppWrapperUserInfoAnnotation)
+	}
+	userInfo := authenticationv1.UserInfo{}
+	if err := json.Unmarshal([]byte(recorded), &userInfo); err != nil {
+		return nil, fmt.Errorf("cannot impersonate the creator of the AppWrapper: malformed annotation %v: %w", awv1beta2.AppWrapperUserInfoAnnotation, err)
+	}
+	if userInfo.Username == "" {
+		return nil, fmt.Errorf("cannot impersonate the creator of the AppWrapper: annotation %v has no username", awv1beta2.AppWrapperUserInfoAnnotation)
+	}
+	rt, err := r.sharedImpersonationTransport()
+	if err != nil {
+		return nil, err
+	}
+	httpClient := &http.Client{
+		Transport: transport.NewImpersonatingRoundTripper(transport.ImpersonationConfig{UserName: userInfo.Username, UID: userInfo.UID, Groups: userInfo.Groups}, rt),
+		Timeout:   r.ImpersonationConfig.Timeout,
+	}
+	return client.New(r.ImpersonationConfig, client.Options{HTTPClient: httpClient, Scheme: r.Scheme, Mapper: r.RESTMapper()})
+}
+
+// sharedImpersonationTransport returns the transport built from ImpersonationConfig, building it on first use
+func (r *AppWrapperReconciler) sharedImpersonationTransport() (http.RoundTripper, error) {
+	r.impersonationMutex.Lock()
+	defer r.impersonationMutex.Unlock()
+	if r.impersonationTransport == nil {
+		rt, err := rest.TransportFor(r.ImpersonationConfig)
+		if err != nil {
+			return nil, err
+		}
+		r.impersonationTransport = rt
+	}
+	return r.impersonationTransport, nil
+}
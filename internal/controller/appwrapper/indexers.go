@@ -0,0 +1,109 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+)
+
+const (
+	// PodAppWrapperIndex indexes Pods by the UID of the AppWrapper that owns them
+	PodAppWrapperIndex = "pod.appwrapper"
+	// legacyPodIndexPrefix marks PodAppWrapperIndex keys of Pods that only carry an AppWrapperLabel.
+	// UIDs never contain a '/', so these keys cannot collide with UID keys.
+	legacyPodIndexPrefix = "legacy/"
+	// PodNodeNameIndex indexes Pods by the name of the Node they are bound to.
+	// The key deliberately matches the field selector supported by the API server.
+	PodNodeNameIndex = "spec.nodeName"
+)
+
+// IndexPodByAppWrapper extracts the value of the AppWrapperUIDLabel from a Pod.
+// Pods created by controller versions that predate the AppWrapperUIDLabel are
+// indexed by the value of their AppWrapperLabel instead (see legacyPodIndexKey).
+func IndexPodByAppWrapper(obj client.Object) []string {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil
+	}
+	if uid, ok := pod.Labels[awv1beta2.AppWrapperUIDLabel]; ok && uid != "" {
+		return []string{uid}
+	}
+	if name, ok := pod.Labels[awv1beta2.AppWrapperLabel]; ok && name != "" {
+		return []string{legacyPodIndexKey(name)}
+	}
+	return nil
+}
+
+// legacyPodIndexKey is the PodAppWrapperIndex key of the Pods of AppWrapper awName that lack an AppWrapperUIDLabel
+func legacyPodIndexKey(awName string) string {
+	return legacyPodIndexPrefix + awName
+}
+
+// IndexPodByNodeName extracts the name of the Node a Pod is bound to
+func IndexPodByNodeName(obj client.Object) []string {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.Spec.NodeName == "" {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
+// attemptIndex identifies the current deployment attempt of an AppWrapper.
+// It is stamped into the AppWrapperAttemptLabel of every Pod created by createComponent.
+// Pods without an AppWrapperAttemptLabel predate the label and are attributed to the current attempt.
+func attemptIndex(aw *awv1beta2.AppWrapper) string {
+	return strconv.Itoa(int(aw.Status.Attempts))
+}
+
+// beginAttempt starts a new deployment attempt of aw
+func beginAttempt(aw *awv1beta2.AppWrapper) {
+	aw.Status.Attempts += 1
+}
+
+// listAppWrapperPods lists the Pods that belong to the current incarnation of aw.
+//
+// Pods created before the AppWrapperUIDLabel was introduced only carry an AppWrapperLabel.
+// To allow AppWrappers deployed by an earlier version of the controller to run to completion,
+// such Pods are also included provided they were created after aw itself.
+// A Pod of a previous incarnation with the same name must have been created before aw. Because creation
+// timestamps have a resolution of one second, Pods created in the same second as aw are excluded too.
+func (r *AppWrapperReconciler) listAppWrapperPods(ctx context.Context, aw *awv1beta2.AppWrapper, opts ...client.ListOption) (*v1.PodList, error) {
+	pods := &v1.PodList{Items: []v1.Pod{}}
+	if err := r.List(ctx, pods, append(opts,
+		client.InNamespace(aw.Namespace),
+		client.MatchingFields{PodAppWrapperIndex: string(aw.UID)})...); err != nil {
+		return nil, err
+	}
+	legacyPods := &v1.PodList{}
+	if err := r.List(ctx, legacyPods, append(opts,
+		client.InNamespace(aw.Namespace),
+		client.MatchingFields{PodAppWrapperIndex: legacyPodIndexKey(aw.Name)})...); err != nil {
+		return nil, err
+	}
+	for _, pod := range legacyPods.Items {
+		if aw.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			pods.Items = append(pods.Items, pod)
+		}
+	}
+	return pods, nil
+}
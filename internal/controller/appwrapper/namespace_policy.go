@@ -0,0 +1,104 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/pkg/utils"
+)
+
+// namespacePolicy returns the annotations of the AppWrapper's namespace, which
+// supply namespace-level defaults and maximums for its fault tolerance parameters
+func (r *AppWrapperReconciler) namespacePolicy(ctx context.Context, aw *awv1beta2.AppWrapper) map[string]string {
+	if !r.Config.Get().NamespacePolicies || aw.Namespace == "" {
+		return nil
+	}
+	ns := &v1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: aw.Namespace}, ns); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Unable to get namespace policy; using operator defaults", "namespace", aw.Namespace)
+		}
+		return nil
+	}
+	return ns.Annotations
+}
+
+// resolveDuration determines the value of a duration-valued fault tolerance parameter.
+// The AppWrapper's annotation takes precedence over its namespace's annotation, which takes precedence over
+// globalDefault. The result is bounded by the namespace's maximum annotation, if present.
+func (r *AppWrapperReconciler) resolveDuration(ctx context.Context, aw *awv1beta2.AppWrapper, annotation string, globalDefault time.Duration) time.Duration {
+	policy := r.namespacePolicy(ctx, aw)
+	resolved := globalDefault
+	if nsPeriod, ok := policy[annotation]; ok {
+		if duration, err := utils.ParseDurationAnnotation(nsPeriod); err == nil {
+			resolved = duration
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed namespace annotation; using operator default", "annotation", annotation, "value", nsPeriod)
+		}
+	}
+	if userPeriod, ok := aw.Annotations[annotation]; ok {
+		if duration, err := utils.ParseDurationAnnotation(userPeriod); err == nil {
+			resolved = duration
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed annotation; using default", "annotation", annotation, "value", userPeriod)
+		}
+	}
+	if nsMaximum, ok := policy[annotation+awv1beta2.MaximumAnnotationSuffix]; ok {
+		if maximum, err := utils.ParseDurationAnnotation(nsMaximum); err == nil {
+			resolved = min(resolved, maximum)
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed namespace maximum annotation; ignoring", "annotation", annotation+awv1beta2.MaximumAnnotationSuffix, "value", nsMaximum)
+		}
+	}
+	return resolved
+}
+
+// resolveLimit is the analogue of resolveDuration for integer-valued fault tolerance parameters
+func (r *AppWrapperReconciler) resolveLimit(ctx context.Context, aw *awv1beta2.AppWrapper, annotation string, globalDefault int32) int32 {
+	policy := r.namespacePolicy(ctx, aw)
+	resolved := globalDefault
+	if nsLimit, ok := policy[annotation]; ok {
+		if limit, err := utils.ParseLimitAnnotation(nsLimit); err == nil {
+			resolved = limit
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed namespace annotation; using operator default", "annotation", annotation, "value", nsLimit)
+		}
+	}
+	if userLimit, ok := aw.Annotations[annotation]; ok {
+		if limit, err := utils.ParseLimitAnnotation(userLimit); err == nil {
+			resolved = limit
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed annotation; using default", "annotation", annotation, "value", userLimit)
+		}
+	}
+	if nsMaximum, ok := policy[annotation+awv1beta2.MaximumAnnotationSuffix]; ok {
+		if maximum, err := utils.ParseLimitAnnotation(nsMaximum); err == nil {
+			resolved = min(resolved, maximum)
+		} else {
+			log.FromContext(ctx).Error(err, "Malformed namespace maximum annotation; ignoring", "annotation", annotation+awv1beta2.MaximumAnnotationSuffix, "value", nsMaximum)
+		}
+	}
+	return resolved
+}
@@ -20,16 +20,20 @@ import (
 	"context"
 	"maps"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
 	"github.com/project-codeflare/appwrapper/pkg/config"
 )
 
@@ -40,7 +44,11 @@ import (
 // a designated slack ClusterQueue and to migrate running workloads away from NoExecute resources.
 type NodeHealthMonitor struct {
 	client.Client
-	Config *config.AppWrapperConfig
+	Config *config.SharedAppWrapperConfig
+	Events chan event.GenericEvent // event channel for NodeHealthMonitor to trigger reconciliation of affected AppWrappers
+
+	untriggeredNodesMutex sync.Mutex
+	untriggeredNodes      sets.Set[string] // NoExecute Nodes whose AppWrappers could not all be triggered because Events was full
 }
 
 var (
@@ -73,8 +81,11 @@ func (r *NodeHealthMonitor) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if node.DeletionTimestamp.IsZero() {
-		r.updateNoExecuteNodes(ctx, node)
+		triggered := r.updateNoExecuteNodes(ctx, node)
 		r.updateNoScheduleNodes(ctx, node)
+		if !triggered {
+			return ctrl.Result{RequeueAfter: time.Second}, nil // retry once the AppWrapper controller has drained Events
+		}
 	} else {
 		r.updateForNodeDeletion(ctx, req.Name)
 	}
@@ -84,6 +95,7 @@ func (r *NodeHealthMonitor) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 // update noExecuteNodes and noScheduleNodes for the deletion of nodeName
 func (r *NodeHealthMonitor) updateForNodeDeletion(ctx context.Context, nodeName string) {
+	r.setUntriggered(nodeName, false)
 	if _, ok := noExecuteNodes[nodeName]; ok {
 		noExecuteNodesMutex.Lock() // BEGIN CRITICAL SECTION
 		delete(noExecuteNodes, nodeName)
@@ -100,11 +112,13 @@ func (r *NodeHealthMonitor) updateForNodeDeletion(ctx context.Context, nodeName
 	}
 }
 
-// update noExecuteNodes entry for node
-func (r *NodeHealthMonitor) updateNoExecuteNodes(ctx context.Context, node *v1.Node) {
+// update noExecuteNodes entry for node and trigger the reconciliation of the AppWrappers on node if it became NoExecute.
+// Returns false if some of these AppWrappers could not be triggered yet.
+func (r *NodeHealthMonitor) updateNoExecuteNodes(ctx context.Context, node *v1.Node) bool {
 	noExecuteResources := make(sets.Set[string])
+	resourceTaints := r.Config.Get().Autopilot.ResourceTaints
 	for key, value := range node.GetLabels() {
-		for resourceName, taints := range r.Config.Autopilot.ResourceTaints {
+		for resourceName, taints := range resourceTaints {
 			for _, taint := range taints {
 				if key == taint.Key && value == taint.Value && taint.Effect == v1.TaintEffectNoExecute {
 					noExecuteResources.Insert(resourceName)
@@ -132,6 +146,65 @@ func (r *NodeHealthMonitor) updateNoExecuteNodes(ctx context.Context, node *v1.N
 	if noExecuteNodesChanged {
 		log.FromContext(ctx).Info("Updated NoExecute information", "Number NoExecute Nodes", len(noExecuteNodes), "NoExecute Resource Details", noExecuteNodes)
 	}
+	if len(noExecuteResources) == 0 {
+		r.setUntriggered(node.GetName(), false)
+		return true
+	}
+	if noExecuteNodesChanged || r.isUntriggered(node.GetName()) {
+		triggered := r.triggerAppWrappersOnNode(ctx, node.GetName())
+		r.setUntriggered(node.GetName(), !triggered)
+		return triggered
+	}
+	return true
+}
+
+// triggerAppWrappersOnNode requests the reconciliation of every AppWrapper with a Pod bound to nodeName.
+// It never blocks the NodeHealthMonitor; it returns false if Events is full and some AppWrappers were not triggered.
+func (r *NodeHealthMonitor) triggerAppWrappersOnNode(ctx context.Context, nodeName string) bool {
+	if r.Events == nil {
+		return true
+	}
+	pods := &v1.PodList{}
+	if err := r.List(ctx, pods, client.UnsafeDisableDeepCopy, client.MatchingFields{PodNodeNameIndex: nodeName}); err != nil {
+		log.FromContext(ctx).Error(err, "Pod list error", "node", nodeName)
+		return true
+	}
+	keys := sets.New[client.ObjectKey]()
+	for _, pod := range pods.Items {
+		if awName, ok := pod.Labels[awv1beta2.AppWrapperLabel]; ok {
+			keys.Insert(client.ObjectKey{Namespace: pod.Namespace, Name: awName})
+		}
+	}
+	for key := range keys {
+		select {
+		case r.Events <- event.GenericEvent{Object: &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}}:
+		default:
+			log.FromContext(ctx).Info("AppWrapper event channel is full; will retry", "node", nodeName)
+			return false
+		}
+	}
+	return true
+}
+
+// isUntriggered returns true if the AppWrappers on nodeName could not all be triggered
+func (r *NodeHealthMonitor) isUntriggered(nodeName string) bool {
+	r.untriggeredNodesMutex.Lock()
+	defer r.untriggeredNodesMutex.Unlock()
+	return r.untriggeredNodes.Has(nodeName)
+}
+
+// setUntriggered records whether the AppWrappers on nodeName could not all be triggered
+func (r *NodeHealthMonitor) setUntriggered(nodeName string, untriggered bool) {
+	r.untriggeredNodesMutex.Lock()
+	defer r.untriggeredNodesMutex.Unlock()
+	if untriggered {
+		if r.untriggeredNodes == nil {
+			r.untriggeredNodes = sets.New[string]()
+		}
+		r.untriggeredNodes.Insert(nodeName)
+	} else {
+		r.untriggeredNodes.Delete(nodeName)
+	}
 }
 
 // update noScheduleNodes entry for node
@@ -142,8 +215,9 @@ func (r *NodeHealthMonitor) updateNoScheduleNodes(ctx context.Context, node *v1.
 		delete(noScheduleResources, v1.ResourcePods)
 	} else {
 		noScheduleResources = make(v1.ResourceList)
+		resourceTaints := r.Config.Get().Autopilot.ResourceTaints
 		for key, value := range node.GetLabels() {
-			for resourceName, taints := range r.Config.Autopilot.ResourceTaints {
+			for resourceName, taints := range resourceTaints {
 				for _, taint := range taints {
 					if taint.Effect == v1.TaintEffectNoExecute || taint.Effect == v1.TaintEffectNoSchedule {
 						if key == taint.Key && value == taint.Value {
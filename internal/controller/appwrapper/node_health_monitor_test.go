@@ -21,8 +21,11 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
 	"github.com/project-codeflare/appwrapper/pkg/config"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -64,7 +67,8 @@ var _ = Describe("NodeMonitor Controller", func() {
 		awConfig := config.NewAppWrapperConfig()
 		nodeMonitor = &NodeHealthMonitor{
 			Client: k8sClient,
-			Config: awConfig,
+			Config: config.NewSharedAppWrapperConfig(awConfig),
+			Events: make(chan event.GenericEvent, 10),
 		}
 	})
 
@@ -171,4 +175,100 @@ var _ = Describe("NodeMonitor Controller", func() {
 		deleteNode(node1Name.Name)
 		deleteNode(node2Name.Name)
 	})
+
+	It("NoExecute Nodes trigger reconciliation of their AppWrappers", func() {
+		createNode(node1Name.Name)
+		createNode(node2Name.Name)
+
+		awPod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: randName("pod"), Namespace: "default", Labels: map[string]string{awv1beta2.AppWrapperLabel: "aw-on-node-1"}},
+			Spec: v1.PodSpec{
+				NodeName:   node1Name.Name,
+				Containers: []v1.Container{{Name: "busybox", Image: "quay.io/project-codeflare/busybox:1.36"}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, awPod)).To(Succeed())
+
+		By("A node labeled EVICT triggers the AppWrappers of its Pods")
+		node := getNode(node1Name.Name)
+		node.Labels["autopilot.ibm.com/gpuhealth"] = "EVICT"
+		Expect(k8sClient.Update(ctx, node)).Should(Succeed())
+		_, err := nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeMonitor.Events).Should(HaveLen(1))
+		triggered := <-nodeMonitor.Events
+		Expect(triggered.Object.GetNamespace()).Should(Equal(awPod.Namespace))
+		Expect(triggered.Object.GetName()).Should(Equal("aw-on-node-1"))
+
+		By("A NoExecute node with no AppWrapper Pods triggers nothing")
+		node = getNode(node2Name.Name)
+		node.Labels["autopilot.ibm.com/gpuhealth"] = "EVICT"
+		Expect(k8sClient.Update(ctx, node)).Should(Succeed())
+		_, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node2Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(nodeMonitor.Events).Should(BeEmpty())
+
+		Expect(k8sClient.Delete(ctx, awPod, client.GracePeriodSeconds(0))).To(Succeed())
+		deleteNode(node1Name.Name)
+		deleteNode(node2Name.Name)
+		_, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node2Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(noExecuteNodes).Should(BeEmpty())
+	})
+
+	It("Triggering AppWrappers never blocks on a full event channel", func() {
+		nodeMonitor.Events = make(chan event.GenericEvent, 1)
+		createNode(node1Name.Name)
+
+		var awPods []*v1.Pod
+		for _, awName := range []string{"aw-1-on-node-1", "aw-2-on-node-1"} {
+			awPod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: randName("pod"), Namespace: "default", Labels: map[string]string{awv1beta2.AppWrapperLabel: awName}},
+				Spec: v1.PodSpec{
+					NodeName:   node1Name.Name,
+					Containers: []v1.Container{{Name: "busybox", Image: "quay.io/project-codeflare/busybox:1.36"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, awPod)).To(Succeed())
+			awPods = append(awPods, awPod)
+		}
+
+		By("The NodeHealthMonitor requeues the Node instead of waiting for the channel to drain")
+		node := getNode(node1Name.Name)
+		node.Labels["autopilot.ibm.com/gpuhealth"] = "EVICT"
+		Expect(k8sClient.Update(ctx, node)).Should(Succeed())
+		result, err := nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeNumerically(">", 0))
+		Expect(nodeMonitor.Events).Should(HaveLen(1))
+		<-nodeMonitor.Events
+
+		By("The requeued reconciliation triggers the AppWrappers again")
+		result, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeNumerically(">", 0))
+		Expect(nodeMonitor.Events).Should(HaveLen(1))
+		<-nodeMonitor.Events
+
+		By("Once the channel has room for every AppWrapper, no further reconciliation is needed")
+		nodeMonitor.Events = make(chan event.GenericEvent, 10)
+		result, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeZero())
+		Expect(nodeMonitor.Events).Should(HaveLen(2))
+		result, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).Should(BeZero())
+		Expect(nodeMonitor.Events).Should(HaveLen(2), "AppWrappers are only triggered when the Node becomes NoExecute")
+
+		for _, awPod := range awPods {
+			Expect(k8sClient.Delete(ctx, awPod, client.GracePeriodSeconds(0))).To(Succeed())
+		}
+		deleteNode(node1Name.Name)
+		_, err = nodeMonitor.Reconcile(ctx, reconcile.Request{NamespacedName: node1Name})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(noExecuteNodes).Should(BeEmpty())
+	})
 })
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
@@ -187,10 +188,13 @@ func addNodeSelectorsToAffinity(spec map[string]interface{}, exprsToAdd []v1.Nod
 	return nil
 }
 
+// prepareComponent constructs the object for a component by injecting the AppWrapper's labels and the PodSetInfos into its Template
+//
 //gocyclo:ignore
-func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta2.AppWrapper, componentIdx int) (error, bool) {
+func (r *AppWrapperReconciler) prepareComponent(ctx context.Context, aw *awv1beta2.AppWrapper, componentIdx int) (*unstructured.Unstructured, error, bool) {
 	component := aw.Spec.Components[componentIdx]
 	componentStatus := aw.Status.ComponentStatus[componentIdx]
+	cfg := r.Config.Get()
 	toMap := func(x interface{}) map[string]string {
 		if x == nil {
 			return nil
@@ -216,9 +220,13 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 
 	obj, err := parseComponent(component.Template.Raw, aw.Namespace)
 	if err != nil {
-		return err, true
+		return nil, err, true
+	}
+	awLabels := map[string]string{
+		awv1beta2.AppWrapperLabel:        aw.Name,
+		awv1beta2.AppWrapperUIDLabel:     string(aw.UID),
+		awv1beta2.AppWrapperAttemptLabel: attemptIndex(aw),
 	}
-	awLabels := map[string]string{awv1beta2.AppWrapperLabel: aw.Name}
 	obj.SetLabels(utilmaps.MergeKeepFirst(obj.GetLabels(), awLabels))
 
 	for podSetsIdx, podSet := range componentStatus.PodSets {
@@ -229,7 +237,7 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 
 		p, err := utils.GetRawTemplate(obj.UnstructuredContent(), podSet.Path)
 		if err != nil {
-			return err, true // Should not happen, path validity is enforced by validateAppWrapperInvariants
+			return nil, err, true // Should not happen, path validity is enforced by validateAppWrapperInvariants
 		}
 		if md, ok := p["metadata"]; !ok || md == nil {
 			p["metadata"] = make(map[string]interface{})
@@ -241,7 +249,7 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 		if len(toInject.Annotations) > 0 {
 			existing := toMap(metadata["annotations"])
 			if err := utilmaps.HaveConflict(existing, toInject.Annotations); err != nil {
-				return fmt.Errorf("conflict updating annotations: %w", err), true
+				return nil, fmt.Errorf("conflict updating annotations: %w", err), true
 			}
 			metadata["annotations"] = utilmaps.MergeKeepFirst(existing, toInject.Annotations)
 		}
@@ -250,7 +258,7 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 		mergedLabels := utilmaps.MergeKeepFirst(toInject.Labels, awLabels)
 		existing := toMap(metadata["labels"])
 		if err := utilmaps.HaveConflict(existing, mergedLabels); err != nil {
-			return fmt.Errorf("conflict updating labels: %w", err), true
+			return nil, fmt.Errorf("conflict updating labels: %w", err), true
 		}
 		metadata["labels"] = utilmaps.MergeKeepFirst(existing, mergedLabels)
 
@@ -258,7 +266,7 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 		if len(toInject.NodeSelector) > 0 {
 			existing := toMap(spec["nodeSelector"])
 			if err := utilmaps.HaveConflict(existing, toInject.NodeSelector); err != nil {
-				return fmt.Errorf("conflict updating nodeSelector: %w", err), true
+				return nil, fmt.Errorf("conflict updating nodeSelector: %w", err), true
 			}
 			spec["nodeSelector"] = utilmaps.MergeKeepFirst(existing, toInject.NodeSelector)
 		}
@@ -301,16 +309,16 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 		}
 
 		// Scheduler Name
-		if r.Config.SchedulerName != "" {
+		if cfg.SchedulerName != "" {
 			if existing, _ := spec["schedulerName"].(string); existing == "" {
-				spec["schedulerName"] = r.Config.SchedulerName
+				spec["schedulerName"] = cfg.SchedulerName
 			}
 		}
 
-		if r.Config.Autopilot != nil && r.Config.Autopilot.InjectAntiAffinities {
+		if cfg.Autopilot != nil && cfg.Autopilot.InjectAntiAffinities {
 			toAddRequired := map[string][]string{}
 			toAddPreferred := map[string][]string{}
-			for resource, taints := range r.Config.Autopilot.ResourceTaints {
+			for resource, taints := range cfg.Autopilot.ResourceTaints {
 				if hasResourceRequest(spec, resource) {
 					for _, taint := range taints {
 						if taint.Effect == v1.TaintEffectNoExecute || taint.Effect == v1.TaintEffectNoSchedule {
@@ -335,7 +343,7 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 				for k, v := range toAddPreferred {
 					matchExpressions = append(matchExpressions, v1.NodeSelectorRequirement{Operator: v1.NodeSelectorOpNotIn, Key: k, Values: v})
 				}
-				weight := ptr.Deref(r.Config.Autopilot.PreferNoScheduleWeight, 1)
+				weight := ptr.Deref(cfg.Autopilot.PreferNoScheduleWeight, 1)
 				if err := addNodeSelectorsToAffinity(spec, matchExpressions, false, weight); err != nil {
 					log.FromContext(ctx).Error(err, "failed to inject Autopilot affinities")
 				}
@@ -344,25 +352,24 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 	}
 
 	if err := controllerutil.SetControllerReference(aw, obj, r.Scheme); err != nil {
-		return err, true
+		return nil, err, true
 	}
 
-	orig := copyForStatusPatch(aw)
-	if meta.FindStatusCondition(aw.Status.ComponentStatus[componentIdx].Conditions, string(awv1beta2.ResourcesDeployed)) == nil {
-		aw.Status.ComponentStatus[componentIdx].Name = obj.GetName()
-		aw.Status.ComponentStatus[componentIdx].Kind = obj.GetKind()
-		aw.Status.ComponentStatus[componentIdx].APIVersion = obj.GetAPIVersion()
+	return obj, nil, false
+}
+
+// createComponent creates obj and records the outcome in aw.Status without patching it
+func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta2.AppWrapper, componentIdx int, obj *unstructured.Unstructured) (error, bool) {
+	creator, err := r.componentCreator(aw)
+	if err != nil {
 		meta.SetStatusCondition(&aw.Status.ComponentStatus[componentIdx].Conditions, metav1.Condition{
 			Type:   string(awv1beta2.ResourcesDeployed),
-			Status: metav1.ConditionUnknown,
-			Reason: "ComponentCreationInitiated",
+			Status: metav1.ConditionFalse,
+			Reason: "ComponentCreationErrored",
 		})
-		if err := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); err != nil {
-			return err, false
-		}
+		return err, true // fatal
 	}
-
-	if err := r.Create(ctx, obj); err != nil {
+	if err := creator.Create(ctx, obj); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			// obj is not updated if Create returns an error; Get required for accurate information
 			if err := r.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
@@ -374,41 +381,66 @@ func (r *AppWrapperReconciler) createComponent(ctx context.Context, aw *awv1beta
 			}
 			// fall through.  This is not actually an error. The object already exists and the correct appwrapper owns it.
 		} else {
-			// resource not actually created; patch status to reflect that
-			orig := copyForStatusPatch(aw)
+			// resource not actually created; record that in the status
 			meta.SetStatusCondition(&aw.Status.ComponentStatus[componentIdx].Conditions, metav1.Condition{
 				Type:   string(awv1beta2.ResourcesDeployed),
 				Status: metav1.ConditionFalse,
 				Reason: "ComponentCreationErrored",
 			})
-			if patchErr := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); patchErr != nil {
-				// ugh.  Patch failed, so retry the create so we can get to a consistient state
-				return patchErr, false
-			}
-			// return actual error
 			return err, meta.IsNoMatchError(err) || apierrors.IsInvalid(err) // fatal
 		}
 	}
 
-	orig = copyForStatusPatch(aw)
 	aw.Status.ComponentStatus[componentIdx].Name = obj.GetName() // Update name to support usage of GenerateName
 	meta.SetStatusCondition(&aw.Status.ComponentStatus[componentIdx].Conditions, metav1.Condition{
 		Type:   string(awv1beta2.ResourcesDeployed),
 		Status: metav1.ConditionTrue,
 		Reason: "ComponentCreatedSuccessfully",
 	})
-	if err := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); err != nil {
-		return err, false
-	}
-
 	return nil, false
 }
 
-// createComponents incrementally patches aw.Status -- MUST NOT CARRY STATUS PATCHES ACROSS INVOCATIONS
+// createComponents creates all components that are not yet deployed.
+// Before creating any resources, the intent to create them is recorded with a single status patch
+// (the write-ahead intent ensures deleteComponents can find every resource even if the controller crashes).
+// The outcome of the creations is only recorded in aw.Status; the caller MUST persist it with a Status().Patch.
 func (r *AppWrapperReconciler) createComponents(ctx context.Context, aw *awv1beta2.AppWrapper) (error, bool) {
+	toCreate := make(map[int]*unstructured.Unstructured, len(aw.Spec.Components))
 	for componentIdx := range aw.Spec.Components {
 		if !meta.IsStatusConditionTrue(aw.Status.ComponentStatus[componentIdx].Conditions, string(awv1beta2.ResourcesDeployed)) {
-			if err, fatal := r.createComponent(ctx, aw, componentIdx); err != nil {
+			obj, err, fatal := r.prepareComponent(ctx, aw, componentIdx)
+			if err != nil {
+				return err, fatal
+			}
+			toCreate[componentIdx] = obj
+		}
+	}
+
+	orig := copyForStatusPatch(aw)
+	intentRecorded := false
+	for componentIdx, obj := range toCreate {
+		cs := &aw.Status.ComponentStatus[componentIdx]
+		if meta.FindStatusCondition(cs.Conditions, string(awv1beta2.ResourcesDeployed)) == nil {
+			cs.Name = obj.GetName()
+			cs.Kind = obj.GetKind()
+			cs.APIVersion = obj.GetAPIVersion()
+			meta.SetStatusCondition(&cs.Conditions, metav1.Condition{
+				Type:   string(awv1beta2.ResourcesDeployed),
+				Status: metav1.ConditionUnknown,
+				Reason: "ComponentCreationInitiated",
+			})
+			intentRecorded = true
+		}
+	}
+	if intentRecorded {
+		if err := r.Status().Patch(ctx, aw, client.MergeFrom(orig)); err != nil {
+			return err, false
+		}
+	}
+
+	for componentIdx := range aw.Spec.Components {
+		if obj, ok := toCreate[componentIdx]; ok {
+			if err, fatal := r.createComponent(ctx, aw, componentIdx, obj); err != nil {
 				return err, fatal
 			}
 		}
@@ -416,6 +448,64 @@ func (r *AppWrapperReconciler) createComponents(ctx context.Context, aw *awv1bet
 	return nil, false
 }
 
+// signalSuspension gives the workload of a suspending AppWrapper a window of gracePeriod to checkpoint before its components
+// are deleted. The active Pods of the current attempt are annotated with the deadline of the window.
+// It returns true when the window has expired or when every such Pod has terminated or reported that its checkpoint is complete;
+// otherwise it returns false and the duration after which the Pods should be checked again.
+func (r *AppWrapperReconciler) signalSuspension(ctx context.Context, aw *awv1beta2.AppWrapper, gracePeriod time.Duration) (bool, time.Duration) {
+	cond := meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.DeletingResources))
+	if cond == nil || cond.Reason != "SuspensionGracePeriod" {
+		// remove the condition first to ensure its LastTransitionTime records the start of this window
+		meta.RemoveStatusCondition(&aw.Status.Conditions, string(awv1beta2.DeletingResources))
+		meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+			Type:    string(awv1beta2.DeletingResources),
+			Status:  metav1.ConditionFalse,
+			Reason:  "SuspensionGracePeriod",
+			Message: fmt.Sprintf("Waiting up to %v for the workload to checkpoint", gracePeriod),
+		})
+		cond = meta.FindStatusCondition(aw.Status.Conditions, string(awv1beta2.DeletingResources))
+	}
+
+	now := time.Now()
+	deadline := cond.LastTransitionTime.Add(gracePeriod)
+	if !now.Before(deadline) {
+		return true, 0
+	}
+
+	pods, err := r.listAppWrapperPods(ctx, aw)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Pod list error")
+		return false, 5 * time.Second
+	}
+	deadlineValue := deadline.UTC().Format(time.RFC3339)
+	currentAttempt := attemptIndex(aw)
+	done := true
+	for _, pod := range pods.Items {
+		if attempt, ok := pod.Labels[awv1beta2.AppWrapperAttemptLabel]; ok && attempt != currentAttempt {
+			continue // a lingering Pod of a previous attempt
+		}
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if checkpointed, err := strconv.ParseBool(pod.Annotations[awv1beta2.CheckpointCompleteAnnotation]); err == nil && checkpointed {
+			continue
+		}
+		done = false
+		if pod.Annotations[awv1beta2.SuspensionDeadlineAnnotation] != deadlineValue {
+			patch := client.MergeFrom(pod.DeepCopy())
+			metav1.SetMetaDataAnnotation(&pod.ObjectMeta, awv1beta2.SuspensionDeadlineAnnotation, deadlineValue)
+			if err := r.Patch(ctx, &pod, patch); err != nil && !apierrors.IsNotFound(err) {
+				log.FromContext(ctx).Error(err, "Pod annotation error", "pod", pod.Name)
+			}
+		}
+	}
+	if done {
+		return true, 0
+	}
+	// Pods report their checkpoints by changing their annotations, which does not trigger a reconcile; poll them
+	return false, min(deadline.Sub(now), 5*time.Second)
+}
+
 func (r *AppWrapperReconciler) deleteComponents(ctx context.Context, aw *awv1beta2.AppWrapper) bool {
 	deleteIfPresent := func(idx int, opts ...client.DeleteOption) bool {
 		cs := &aw.Status.ComponentStatus[idx]
@@ -464,12 +554,10 @@ func (r *AppWrapperReconciler) deleteComponents(ctx context.Context, aw *awv1bet
 		return false
 	}
 
-	pods := &v1.PodList{Items: []v1.Pod{}}
-	if err := r.List(ctx, pods,
-		client.UnsafeDisableDeepCopy,
-		client.InNamespace(aw.Namespace),
-		client.MatchingLabels{awv1beta2.AppWrapperLabel: aw.Name}); err != nil {
+	pods, err := r.listAppWrapperPods(ctx, aw, client.UnsafeDisableDeepCopy)
+	if err != nil {
 		log.FromContext(ctx).Error(err, "Pod list error")
+		pods = &v1.PodList{Items: []v1.Pod{}}
 	}
 
 	if !componentsRemaining && len(pods.Items) == 0 {
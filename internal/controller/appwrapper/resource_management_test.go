@@ -0,0 +1,122 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/events"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/pkg/config"
+	"github.com/project-codeflare/appwrapper/pkg/utils"
+)
+
+// apiCallCounter counts the API writes issued by a reconciler
+type apiCallCounter struct {
+	creates       int
+	statusPatches int
+}
+
+func (c *apiCallCounter) funcs() interceptor.Funcs {
+	return interceptor.Funcs{
+		Create: func(ctx context.Context, cl client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			c.creates += 1
+			return cl.Create(ctx, obj, opts...)
+		},
+		SubResourcePatch: func(ctx context.Context, cl client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+			if subResourceName == "status" {
+				c.statusPatches += 1
+			}
+			return cl.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+		},
+	}
+}
+
+// resumingAppWrapper returns an AppWrapper with numComponents Pods that is ready to enter the Resuming phase
+func resumingAppWrapper(b *testing.B, numComponents int) *awv1beta2.AppWrapper {
+	aw := &awv1beta2.AppWrapper{
+		TypeMeta:   metav1.TypeMeta{APIVersion: awv1beta2.GroupVersion.String(), Kind: awv1beta2.AppWrapperKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "bench", Namespace: "default", UID: types.UID("bench-uid")},
+	}
+	for i := range numComponents {
+		raw := fmt.Sprintf(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"bench-pod-%d"},`+
+			`"spec":{"restartPolicy":"Never","containers":[{"name":"busybox","image":"quay.io/project-codeflare/busybox:1.36"}]}}`, i)
+		aw.Spec.Components = append(aw.Spec.Components, awv1beta2.AppWrapperComponent{Template: apimachineryruntime.RawExtension{Raw: []byte(raw)}})
+	}
+	if err := utils.EnsureComponentStatusInitialized(aw); err != nil {
+		b.Fatal(err)
+	}
+	aw.Status.Phase = awv1beta2.AppWrapperResuming
+	meta.SetStatusCondition(&aw.Status.Conditions, metav1.Condition{
+		Type:   string(awv1beta2.ResourcesDeployed),
+		Status: metav1.ConditionTrue,
+		Reason: string(awv1beta2.AppWrapperResuming),
+	})
+	return aw
+}
+
+// BenchmarkResumingToRunning measures the API writes needed to deploy the components of an AppWrapper.
+// Run with: go test -run '^$' -bench ResumingToRunning ./internal/controller/appwrapper/
+func BenchmarkResumingToRunning(b *testing.B) {
+	scheme := apimachineryruntime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+	if err := awv1beta2.AddToScheme(scheme); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, numComponents := range []int{1, 8, 32} {
+		b.Run(fmt.Sprintf("components=%d", numComponents), func(b *testing.B) {
+			counter := &apiCallCounter{}
+			for range b.N {
+				b.StopTimer()
+				aw := resumingAppWrapper(b, numComponents)
+				r := &AppWrapperReconciler{
+					Client: fake.NewClientBuilder().
+						WithScheme(scheme).
+						WithStatusSubresource(&awv1beta2.AppWrapper{}).
+						WithObjects(aw).
+						WithInterceptorFuncs(counter.funcs()).
+						Build(),
+					Recorder: &events.FakeRecorder{},
+					Scheme:   scheme,
+					Config:   config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()),
+				}
+				b.StartTimer()
+
+				if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(aw)}); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(counter.creates)/float64(b.N), "creates/op")
+			b.ReportMetric(float64(counter.statusPatches)/float64(b.N), "status-patches/op")
+		})
+	}
+}
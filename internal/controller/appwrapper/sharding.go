@@ -0,0 +1,64 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+)
+
+// rbacs required to shard AppWrappers by the labels of their namespace
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// namespaceSelected returns true if the labels of namespace match selector.
+// A nil selector selects every namespace.
+func namespaceSelected(ctx context.Context, c client.Client, selector labels.Selector, namespace string) (bool, error) {
+	if selector == nil {
+		return true, nil
+	}
+	ns := &v1.Namespace{}
+	if err := c.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// appWrappersInNamespace maps a Namespace to all the AppWrappers it contains,
+// so that a change to the labels of a Namespace moves its AppWrappers between shards
+func appWrappersInNamespace(c client.Client) handler.MapFunc {
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		aws := &awv1beta2.AppWrapperList{}
+		if err := c.List(ctx, aws, client.InNamespace(obj.GetName())); err != nil {
+			log.FromContext(ctx).Error(err, "AppWrapper list error", "namespace", obj.GetName())
+			return nil
+		}
+		requests := make([]reconcile.Request, len(aws.Items))
+		for idx, aw := range aws.Items {
+			requests[idx] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&aw)}
+		}
+		return requests
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appwrapper
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Namespace Sharding", func() {
+	It("Namespaces are selected by their labels", func() {
+		ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: randName("shard"), Labels: map[string]string{"shard": "a"}}}
+		Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+
+		selected, err := namespaceSelected(ctx, k8sClient, nil, ns.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).Should(BeTrue())
+
+		selected, err = namespaceSelected(ctx, k8sClient, labels.SelectorFromSet(labels.Set{"shard": "a"}), ns.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).Should(BeTrue())
+
+		selected, err = namespaceSelected(ctx, k8sClient, labels.SelectorFromSet(labels.Set{"shard": "b"}), ns.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).Should(BeFalse())
+
+		selected, err = namespaceSelected(ctx, k8sClient, labels.SelectorFromSet(labels.Set{"shard": "a"}), "no-such-namespace")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selected).Should(BeFalse())
+
+		Expect(k8sClient.Delete(ctx, ns)).To(Succeed())
+	})
+})
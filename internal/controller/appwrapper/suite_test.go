@@ -19,15 +19,22 @@ package appwrapper
 import (
 	"context"
 	"fmt"
+	"maps"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
+	"time"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -44,7 +51,11 @@ import (
 
 var cfg *rest.Config
 var k8sClient client.Client
+var k8sIndexedClient client.Client
 var testEnv *envtest.Environment
+var workerEnv *envtest.Environment
+var workerClient client.Client
+var workerKubeconfig []byte
 var ctx context.Context
 var cancel context.CancelFunc
 
@@ -63,6 +74,7 @@ var _ = BeforeSuite(func() {
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{
 			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+			filepath.Join("testdata", "crds"),
 		},
 		ErrorIfCRDPathMissing: true,
 
@@ -97,6 +109,23 @@ var _ = BeforeSuite(func() {
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
+	k8sIndexedClient, err = newIndexedClient(ctx, cfg, k8sClient)
+	Expect(err).NotTo(HaveOccurred())
+
+	By("bootstrapping a worker cluster for the dispatcher")
+	workerEnv = &envtest.Environment{
+		CRDDirectoryPaths:     testEnv.CRDDirectoryPaths,
+		ErrorIfCRDPathMissing: true,
+		BinaryAssetsDirectory: testEnv.BinaryAssetsDirectory,
+	}
+	workerCfg, err := workerEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+	workerClient, err = client.New(workerCfg, client.Options{Scheme: scheme})
+	Expect(err).NotTo(HaveOccurred())
+	workerAdmin, err := workerEnv.AddUser(envtest.User{Name: "dispatcher", Groups: []string{"system:masters"}}, nil)
+	Expect(err).NotTo(HaveOccurred())
+	workerKubeconfig, err = workerAdmin.KubeConfig()
+	Expect(err).NotTo(HaveOccurred())
 })
 
 var _ = AfterSuite(func() {
@@ -104,4 +133,83 @@ var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	err := testEnv.Stop()
 	Expect(err).NotTo(HaveOccurred())
+	if workerEnv != nil {
+		Expect(workerEnv.Stop()).To(Succeed())
+	}
 })
+
+// indexedClient reads Pods from an informer cache with the Pod field indexes registered by SetupIndexers,
+// as the controller does in production; the API server cannot evaluate field selectors derived from labels.
+// All other objects are read directly. To keep the tests deterministic, List waits until the cache has
+// caught up with the API server.
+type indexedClient struct {
+	client.Client
+	cache cache.Cache
+}
+
+func newIndexedClient(ctx context.Context, cfg *rest.Config, direct client.Client) (*indexedClient, error) {
+	podCache, err := cache.New(cfg, cache.Options{Scheme: direct.Scheme()})
+	if err != nil {
+		return nil, err
+	}
+	if err := podCache.IndexField(ctx, &v1.Pod{}, PodAppWrapperIndex, IndexPodByAppWrapper); err != nil {
+		return nil, err
+	}
+	if err := podCache.IndexField(ctx, &v1.Pod{}, PodNodeNameIndex, IndexPodByNodeName); err != nil {
+		return nil, err
+	}
+	go func() {
+		defer GinkgoRecover()
+		Expect(podCache.Start(ctx)).To(Succeed())
+	}()
+	if !podCache.WaitForCacheSync(ctx) {
+		return nil, fmt.Errorf("pod cache did not sync")
+	}
+	return &indexedClient{Client: direct, cache: podCache}, nil
+}
+
+func (c *indexedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	pods, ok := list.(*v1.PodList)
+	if !ok {
+		return c.Client.List(ctx, list, opts...)
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	return wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 10*time.Second, true, func(ctx context.Context) (bool, error) {
+		if err := c.cache.List(ctx, pods, opts...); err != nil {
+			return false, err
+		}
+		expected, err := c.expectedPods(ctx, listOpts)
+		if err != nil {
+			return false, err
+		}
+		cached := map[types.UID]string{}
+		for _, pod := range pods.Items {
+			cached[pod.UID] = pod.ResourceVersion
+		}
+		return maps.Equal(cached, expected), nil
+	})
+}
+
+// expectedPods returns the resource versions of the Pods the API server returns for listOpts,
+// evaluating the field selectors of the Pod field indexes with the index functions
+func (c *indexedClient) expectedPods(ctx context.Context, listOpts *client.ListOptions) (map[types.UID]string, error) {
+	pods := &v1.PodList{}
+	if err := c.Client.List(ctx, pods, &client.ListOptions{Namespace: listOpts.Namespace, LabelSelector: listOpts.LabelSelector}); err != nil {
+		return nil, err
+	}
+	indexes := map[string]client.IndexerFunc{PodAppWrapperIndex: IndexPodByAppWrapper, PodNodeNameIndex: IndexPodByNodeName}
+	expected := map[types.UID]string{}
+	for _, pod := range pods.Items {
+		matches := true
+		if listOpts.FieldSelector != nil {
+			for _, req := range listOpts.FieldSelector.Requirements() {
+				matches = matches && slices.Contains(indexes[req.Field](&pod), req.Value)
+			}
+		}
+		if matches {
+			expected[pod.UID] = pod.ResourceVersion
+		}
+	}
+	return expected, nil
+}
@@ -28,8 +28,21 @@ var (
 			Help: `The total number of times an appwrapper transitioned to a given phase per namespace.`,
 		}, []string{"namespace", "phase"},
 	)
+	SubjectAccessReviewCacheLookups = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "appwrapper_subjectaccessreview_cache_lookups_total",
+			Help: `The total number of lookups of cached SubjectAccessReview results by the AppWrapper webhook per result (hit or miss).`,
+		}, []string{"result"},
+	)
+	SubjectAccessReviewDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "appwrapper_subjectaccessreview_duration_seconds",
+			Help:    `The latency of the access reviews performed by the AppWrapper webhook per kind of review.`,
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+		}, []string{"kind"},
+	)
 )
 
 func Register() {
-	metrics.Registry.MustRegister(AppWrapperPhaseCounter)
+	metrics.Registry.MustRegister(AppWrapperPhaseCounter, SubjectAccessReviewCacheLookups, SubjectAccessReviewDuration)
 }
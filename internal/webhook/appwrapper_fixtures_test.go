@@ -589,3 +589,79 @@ func rayJobForInference(workerCount int, milliCPU int64) awv1beta2.AppWrapperCom
 		Template: runtime.RawExtension{Raw: jsonBytes},
 	}
 }
+
+const riskyPodYAML = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %v
+spec:
+  restartPolicy: Never
+  containers:
+  - name: busybox
+    image: quay.io/project-codeflare/busybox
+    command: ["sh", "-c", "sleep 10"]`
+
+func riskyPod() awv1beta2.AppWrapperComponent {
+	yamlString := fmt.Sprintf(riskyPodYAML, randName("pod"))
+
+	jsonBytes, err := yaml.YAMLToJSON([]byte(yamlString))
+	Expect(err).NotTo(HaveOccurred())
+	return awv1beta2.AppWrapperComponent{
+		DeclaredPodSets: []awv1beta2.AppWrapperPodSet{{Path: "template"}},
+		Template:        runtime.RawExtension{Raw: jsonBytes},
+	}
+}
+
+const gpuPodYAML = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: %v
+spec:
+  restartPolicy: Never
+  containers:
+  - name: busybox
+    image: quay.io/project-codeflare/busybox:1.36
+    command: ["sh", "-c", "sleep 10"]
+    resources:
+      limits:
+        nvidia.com/gpu: %v`
+
+func gpuPod(gpus int64) awv1beta2.AppWrapperComponent {
+	yamlString := fmt.Sprintf(gpuPodYAML, randName("pod"), gpus)
+
+	jsonBytes, err := yaml.YAMLToJSON([]byte(yamlString))
+	Expect(err).NotTo(HaveOccurred())
+	return awv1beta2.AppWrapperComponent{
+		DeclaredPodSets: []awv1beta2.AppWrapperPodSet{{Path: "template"}},
+		Template:        runtime.RawExtension{Raw: jsonBytes},
+	}
+}
+
+const unknownKindYAML = `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: %v
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+      - name: busybox
+        image: quay.io/project-codeflare/busybox:1.36
+        resources:
+          requests:
+            cpu: 100m`
+
+func unknownKind() awv1beta2.AppWrapperComponent {
+	yamlString := fmt.Sprintf(unknownKindYAML, randName("widget"))
+
+	jsonBytes, err := yaml.YAMLToJSON([]byte(yamlString))
+	Expect(err).NotTo(HaveOccurred())
+	return awv1beta2.AppWrapperComponent{
+		DeclaredPodSets: []awv1beta2.AppWrapperPodSet{{Path: "template.spec.template", Replicas: ptr.To(int32(2))}},
+		Template:        runtime.RawExtension{Raw: jsonBytes},
+	}
+}
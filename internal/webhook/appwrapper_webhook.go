@@ -19,14 +19,30 @@ package webhook
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
 	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
 	discovery "k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes"
 	authClientv1 "k8s.io/client-go/kubernetes/typed/authorization/v1"
@@ -37,8 +53,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/internal/metrics"
 	utilmaps "github.com/project-codeflare/appwrapper/internal/util"
 	"github.com/project-codeflare/appwrapper/pkg/config"
+	"github.com/project-codeflare/appwrapper/pkg/policy"
 	"github.com/project-codeflare/appwrapper/pkg/utils"
 )
 
@@ -53,19 +71,51 @@ var (
 )
 
 type rbacACSupport struct {
-	discoveryClient       *discovery.DiscoveryClient
+	discoveryClient       discovery.DiscoveryInterface
 	subjectAccessReviewer authClientv1.SubjectAccessReviewInterface
 	cacheMutex            sync.RWMutex
 	kindToResourceCache   map[string]string
+	sarCacheMutex         sync.Mutex
+	sarCache              map[string]sarCacheEntry
+}
+
+type sarCacheEntry struct {
+	allowed bool
+	expires time.Time
+}
+
+// sarCacheSweepSize is the size of the SubjectAccessReview cache beyond which expired entries are removed
+const sarCacheSweepSize = 1024
+
+func newRBACACSupport(kubeClient kubernetes.Interface) *rbacACSupport {
+	return &rbacACSupport{
+		discoveryClient:       kubeClient.Discovery(),
+		subjectAccessReviewer: kubeClient.AuthorizationV1().SubjectAccessReviews(),
+		kindToResourceCache:   make(map[string]string),
+		sarCache:              make(map[string]sarCacheEntry),
+	}
 }
 
 type appWrapperWebhook struct {
 	client                 client.Client
-	defaultQueueName       string
+	config                 *config.SharedAppWrapperConfig // for hot-reloadable settings such as DefaultQueueName
 	userRBACAdmissionCheck bool
+	controllerPreflight    bool
+	controllerUsername     string // the identity of the controller, whose permissions controllerPreflight checks
+	impersonateUser        bool   // the controller creates components with the permissions of the AppWrapper's creator
+	controllerName         string
+	managedByAllowList     []string
 
-	// support for userRBACAdmissionCheck; will be nil if it is not enabled
+	// support for userRBACAdmissionCheck and controllerPreflight; will be nil if neither is enabled
 	rbacACSupport *rbacACSupport
+
+	// the AdmissionPolicies of the current configuration, compiled on first use
+	policies atomic.Pointer[compiledPolicies]
+}
+
+type compiledPolicies struct {
+	config   *config.AppWrapperConfig
+	programs []*policy.Program
 }
 
 //+kubebuilder:webhook:path=/mutate-workload-codeflare-dev-v1beta2-appwrapper,mutating=true,failurePolicy=fail,sideEffects=None,groups=workload.codeflare.dev,resources=appwrappers,verbs=create,versions=v1beta2,name=mappwrapper.kb.io,admissionReviewVersions=v1
@@ -75,13 +125,15 @@ var _ admission.Defaulter[*awv1beta2.AppWrapper] = &appWrapperWebhook{}
 // Default fills in default values when an AppWrapper is created:
 //  1. Inject default queue name
 //  2. Ensure Suspend is set appropriately
-//  3. Add labels with the user name and id
+//  3. Add labels with the user name and id and an annotation recording the user's identity
+//  4. Inject the configured controller name as managedBy
+//  5. Request the merging of identical PodSets if the operator is configured to merge them by default
 func (w *appWrapperWebhook) Default(ctx context.Context, aw *awv1beta2.AppWrapper) error {
 	log.FromContext(ctx).V(2).Info("Applying defaults", "job", aw)
 
 	// propagate non-empty default queue name
-	if w.defaultQueueName != "" {
-		aw.Labels = utilmaps.MergeKeepFirst(aw.Labels, map[string]string{QueueNameLabel: w.defaultQueueName})
+	if defaultQueueName := w.config.Get().DefaultQueueName; defaultQueueName != "" {
+		aw.Labels = utilmaps.MergeKeepFirst(aw.Labels, map[string]string{QueueNameLabel: defaultQueueName})
 	}
 
 	// inject labels with user name and id
@@ -94,6 +146,23 @@ func (w *appWrapperWebhook) Default(ctx context.Context, aw *awv1beta2.AppWrappe
 	userUID := utils.SanitizeLabel(userInfo.UID)
 	aw.Labels = utilmaps.MergeKeepFirst(map[string]string{AppWrapperUsernameLabel: username, AppWrapperUserIDLabel: userUID}, aw.Labels)
 
+	// record the unsanitized identity of the user so the controller can impersonate them
+	userInfoBytes, err := json.Marshal(authenticationv1.UserInfo{Username: userInfo.Username, UID: userInfo.UID, Groups: userInfo.Groups})
+	if err != nil {
+		return err
+	}
+	aw.Annotations = utilmaps.MergeKeepFirst(map[string]string{awv1beta2.AppWrapperUserInfoAnnotation: string(userInfoBytes)}, aw.Annotations)
+
+	// make the controller that will manage the AppWrapper explicit
+	if aw.Spec.ManagedBy == nil {
+		aw.Spec.ManagedBy = ptr.To(w.controllerName)
+	}
+
+	// record the default for merging identical PodSets; it must not change once Kueue has seen the AppWrapper's PodSets
+	if w.config.Get().MergeIdenticalPodSets {
+		aw.Annotations = utilmaps.MergeKeepFirst(aw.Annotations, map[string]string{awv1beta2.MergeIdenticalPodSetsAnnotation: "true"})
+	}
+
 	return nil
 }
 
@@ -105,14 +174,44 @@ var _ admission.Validator[*awv1beta2.AppWrapper] = &appWrapperWebhook{}
 func (w *appWrapperWebhook) ValidateCreate(ctx context.Context, aw *awv1beta2.AppWrapper) (admission.Warnings, error) {
 	log.FromContext(ctx).V(2).Info("Validating create", "job", aw)
 	allErrors := w.validateAppWrapperCreate(ctx, aw)
-	return nil, allErrors.ToAggregate()
+	annotationErrors, warnings := w.validateAnnotations(ctx, aw)
+	allErrors = append(allErrors, annotationErrors...)
+	if len(allErrors) == 0 {
+		allErrors = append(allErrors, w.transitiveRBACChecks(ctx, aw)...)
+	}
+	if len(allErrors) == 0 {
+		warnings = append(warnings, w.riskWarnings(ctx, aw)...)
+		policyErrors, policyWarnings := w.evaluatePolicies(ctx, aw)
+		allErrors = append(allErrors, policyErrors...)
+		warnings = append(warnings, policyWarnings...)
+	}
+	return warnings, allErrors.ToAggregate()
 }
 
 // ValidateUpdate validates invariants when an AppWrapper is updated
 func (w *appWrapperWebhook) ValidateUpdate(ctx context.Context, oldAW, newAW *awv1beta2.AppWrapper) (admission.Warnings, error) {
 	log.FromContext(ctx).V(2).Info("Validating update", "job", newAW)
 	allErrors := w.validateAppWrapperUpdate(oldAW, newAW)
-	return nil, allErrors.ToAggregate()
+	var warnings admission.Warnings
+	if !maps.Equal(oldAW.Annotations, newAW.Annotations) {
+		var annotationErrors field.ErrorList
+		annotationErrors, warnings = w.validateAnnotations(ctx, newAW)
+		allErrors = append(allErrors, annotationErrors...)
+	}
+	if len(allErrors) == 0 && w.componentsEditable(oldAW, newAW) && !equality.Semantic.DeepEqual(oldAW.Spec.Components, newAW.Spec.Components) {
+		// The components of a queued or running AppWrapper were edited; validate them exactly as if it were being created
+		allErrors = append(allErrors, w.validateAppWrapperCreate(ctx, newAW)...)
+		if len(allErrors) == 0 {
+			allErrors = append(allErrors, w.transitiveRBACChecks(ctx, newAW)...)
+		}
+		if len(allErrors) == 0 {
+			warnings = append(warnings, w.riskWarnings(ctx, newAW)...)
+			policyErrors, policyWarnings := w.evaluatePolicies(ctx, newAW)
+			allErrors = append(allErrors, policyErrors...)
+			warnings = append(warnings, policyWarnings...)
+		}
+	}
+	return warnings, allErrors.ToAggregate()
 }
 
 // ValidateDelete is a noop for us, but is required to implement the Validator interface
@@ -129,9 +228,18 @@ func (w *appWrapperWebhook) ValidateDelete(context.Context, *awv1beta2.AppWrappe
 //  2. AppWrappers must only contain resources intended for their own namespace
 //  3. AppWrappers must not contain any resources that the user could not create directly
 //  4. Every PodSet must be well-formed: the Path must exist and must be parseable as a PodSpecTemplate
-//  5. AppWrappers must contain between 1 and 8 PodSets (Kueue invariant)
+//  5. AppWrappers must contain between 1 and the configured MaxPodSets PodSets (Kueue invariant)
+//  6. AppWrappers must be managed by the configured controller or one in the managedBy allow-list
 func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *awv1beta2.AppWrapper) field.ErrorList {
 	allErrors := field.ErrorList{}
+	awConfig := w.config.Get()
+
+	// Deny managedBy values that are not in the allow-list
+	if aw.Spec.ManagedBy != nil && *aw.Spec.ManagedBy != w.controllerName && !slices.Contains(w.managedByAllowList, *aw.Spec.ManagedBy) {
+		allowed := append([]string{w.controllerName}, w.managedByAllowList...)
+		allErrors = append(allErrors, field.NotSupported(field.NewPath("spec").Child("managedBy"), *aw.Spec.ManagedBy, allowed))
+	}
+
 	components := aw.Spec.Components
 	componentsPath := field.NewPath("spec").Child("components")
 	podSpecCount := 0
@@ -140,6 +248,7 @@ func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *aw
 		allErrors = append(allErrors, field.InternalError(componentsPath, err))
 	}
 	userInfo := request.UserInfo
+	accessChecks := []func() field.ErrorList{} // remote checks of independent components; run concurrently below
 
 	for idx, component := range components {
 		compPath := componentsPath.Index(idx)
@@ -149,9 +258,20 @@ func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *aw
 			allErrors = append(allErrors, field.Invalid(compPath.Child("template"), component.Template, "failed to decode as JSON"))
 		}
 
-		// 1. Deny nested AppWrappers
+		// 1. Deny nested AppWrappers and kinds that are not wrappable
 		if *gvk == awgvk {
 			allErrors = append(allErrors, field.Forbidden(compPath.Child("template"), "Nested AppWrappers are forbidden"))
+		} else if !awConfig.KindAllowed(*gvk) {
+			kindPath := compPath.Child("template").Child("kind")
+			if slices.ContainsFunc(awConfig.DeniedKinds, func(wk config.WrappableKind) bool { return wk.Matches(*gvk) }) {
+				allErrors = append(allErrors, field.Forbidden(kindPath, fmt.Sprintf("%v is on the operator's deny-list of wrappable kinds", gvk.GroupKind())))
+			} else {
+				allowed := make([]string, len(awConfig.AllowedKinds))
+				for i, wk := range awConfig.AllowedKinds {
+					allowed[i] = wk.String()
+				}
+				allErrors = append(allErrors, field.NotSupported(kindPath, gvk.GroupKind().String(), allowed))
+			}
 		}
 
 		// 2. Forbid creation of resources in other namespaces
@@ -162,38 +282,33 @@ func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *aw
 
 		// 3. RBAC check: Perform SubjectAccessReview to verify user is entitled to create component
 		if w.userRBACAdmissionCheck {
-			ra := authv1.ResourceAttributes{
-				Namespace: aw.Namespace,
-				Verb:      "create",
-				Group:     gvk.Group,
-				Version:   gvk.Version,
-				Resource:  w.lookupResource(gvk),
-			}
-			sar := &authv1.SubjectAccessReview{
-				Spec: authv1.SubjectAccessReviewSpec{
-					ResourceAttributes: &ra,
-					User:               userInfo.Username,
-					UID:                userInfo.UID,
-					Groups:             userInfo.Groups,
-				}}
-			if len(userInfo.Extra) > 0 {
-				sar.Spec.Extra = make(map[string]authv1.ExtraValue, len(userInfo.Extra))
-				for k, v := range userInfo.Extra {
-					sar.Spec.Extra[k] = authv1.ExtraValue(v)
+			accessChecks = append(accessChecks, func() field.ErrorList {
+				ra := authv1.ResourceAttributes{
+					Namespace: aw.Namespace,
+					Verb:      "create",
+					Group:     gvk.Group,
+					Version:   gvk.Version,
+					Resource:  w.lookupResource(gvk),
 				}
-			}
-			sar, err = w.rbacACSupport.subjectAccessReviewer.Create(ctx, sar, metav1.CreateOptions{})
-			if err != nil {
-				allErrors = append(allErrors, field.InternalError(compPath.Child("template"), err))
-			} else {
-				if !sar.Status.Allowed {
+				allowed, err := w.userAuthorized(ctx, userInfo, &ra)
+				if err != nil {
+					return field.ErrorList{field.InternalError(compPath.Child("template"), err)}
+				} else if !allowed {
 					reason := fmt.Sprintf("User %v is not authorized to create %v in %v", userInfo.Username, ra.Resource, ra.Namespace)
-					allErrors = append(allErrors, field.Forbidden(compPath.Child("template"), reason))
+					return field.ErrorList{field.Forbidden(compPath.Child("template"), reason)}
 				}
-			}
+				return nil
+			})
+		}
+
+		// 4. Preflight: verify the cluster serves the component's kind and this controller is entitled to create it
+		if w.controllerPreflight && aw.Spec.ManagedBy != nil && *aw.Spec.ManagedBy == w.controllerName && *gvk != awgvk {
+			accessChecks = append(accessChecks, func() field.ErrorList {
+				return w.preflightComponent(ctx, aw.Namespace, gvk, compPath.Child("template"))
+			})
 		}
 
-		// 4. Every DeclaredPodSet must specify a path within Template to a v1.PodSpecTemplate
+		// 5. Every DeclaredPodSet must specify a path within Template to a v1.PodSpecTemplate
 		podSetsPath := compPath.Child("podSets")
 		for psIdx, ps := range component.DeclaredPodSets {
 			podSetPath := podSetsPath.Index(psIdx)
@@ -205,7 +320,7 @@ func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *aw
 			}
 		}
 
-		// 5. Validate PodSets for known GVKs
+		// 6. Validate PodSets for known GVKs
 		if inferred, err := utils.InferPodSets(unstruct); err != nil {
 			allErrors = append(allErrors, field.Invalid(compPath.Child("template"), component.Template, fmt.Sprintf("error inferring PodSets: %v", err)))
 		} else {
@@ -220,19 +335,382 @@ func (w *appWrapperWebhook) validateAppWrapperCreate(ctx context.Context, aw *aw
 		}
 	}
 
-	// 6. Enforce Kueue limitation that 0 < podSpecCount <= 8
+	// 7. Enforce Kueue limitation that 0 < podSpecCount <= MaxPodSets, counting identical PodSets once if they will be merged
+	if podSpecCount > int(awConfig.MaxPodSets) && len(allErrors) == 0 && utils.MergeIdenticalPodSets(aw) {
+		if _, podSets, err := utils.GetComponentPodSpecs(aw.DeepCopy()); err == nil {
+			podSpecCount = len(podSets)
+		}
+	}
 	if podSpecCount == 0 {
 		allErrors = append(allErrors, field.Invalid(componentsPath, components, "components contains no podspecs"))
 	}
-	if podSpecCount > 8 {
-		allErrors = append(allErrors, field.Invalid(componentsPath, components, fmt.Sprintf("components contains %v podspecs; at most 8 are allowed", podSpecCount)))
+	if podSpecCount > int(awConfig.MaxPodSets) {
+		allErrors = append(allErrors, field.Invalid(componentsPath, components,
+			fmt.Sprintf("components contains %v podspecs; at most %v are allowed", podSpecCount, awConfig.MaxPodSets)))
 	}
 
+	allErrors = append(allErrors, runConcurrently(accessChecks)...)
+
 	return allErrors
 }
 
-// validateAppWrapperUpdate enforces deep immutablity of all fields that were validated by validateAppWrapperCreate
+// runConcurrently runs checks concurrently and returns their errors in the order of checks
+func runConcurrently(checks []func() field.ErrorList) field.ErrorList {
+	results := make([]field.ErrorList, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Go(func() { results[i] = check() })
+	}
+	wg.Wait()
+	return slices.Concat(results...)
+}
+
+// validateAnnotations parses every workload.codeflare.dev.appwrapper/ annotation of an AppWrapper with the
+// parser used by the controller. Malformed annotations are errors. Unknown annotations, which the controller
+// ignores, and values that the controller will clamp to GracePeriodMaximum, SuccessTTL, ActiveDeadlineMaximum,
+// or the maximums annotated on the AppWrapper's namespace are reported as warnings.
+func (w *appWrapperWebhook) validateAnnotations(ctx context.Context, aw *awv1beta2.AppWrapper) (field.ErrorList, admission.Warnings) {
+	allErrors := field.ErrorList{}
+	warnings := admission.Warnings{}
+	annotationsPath := field.NewPath("metadata").Child("annotations")
+	faultTolerance := w.config.Get().FaultTolerance
+	policy := w.namespacePolicy(ctx, aw.Namespace)
+
+	for _, key := range slices.Sorted(maps.Keys(aw.Annotations)) {
+		if !strings.HasPrefix(key, utils.AppWrapperAnnotationPrefix) {
+			continue
+		}
+		value := aw.Annotations[key]
+		path := annotationsPath.Key(key)
+		kind, ok := utils.AppWrapperAnnotations[key]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown annotation will be ignored", key))
+			continue
+		}
+		switch kind {
+		case utils.DurationAnnotation:
+			duration, err := utils.ParseDurationAnnotation(value)
+			if err != nil {
+				allErrors = append(allErrors, field.Invalid(path, value, err.Error()))
+			} else if key == awv1beta2.SuccessTTLAnnotation {
+				if duration <= 0 || duration > faultTolerance.SuccessTTL {
+					warnings = append(warnings, fmt.Sprintf("%s: %v is not between 0 and %v; %v will be used", key, duration, faultTolerance.SuccessTTL, faultTolerance.SuccessTTL))
+				}
+			} else if key == awv1beta2.ActiveDeadlineDurationAnnotation {
+				if maximum := faultTolerance.ActiveDeadlineMaximum; maximum > 0 && (duration <= 0 || duration > maximum) {
+					warnings = append(warnings, fmt.Sprintf("%s: %v is not between 0 and %v; %v will be used", key, duration, maximum, maximum))
+				}
+			} else if duration < 0 {
+				warnings = append(warnings, fmt.Sprintf("%s: %v is negative; 0s will be used", key, duration))
+			} else if duration > faultTolerance.GracePeriodMaximum {
+				warnings = append(warnings, fmt.Sprintf("%s: %v exceeds the maximum grace period; %v will be used", key, duration, faultTolerance.GracePeriodMaximum))
+			}
+			if nsMaximum, ok := policy[key+awv1beta2.MaximumAnnotationSuffix]; ok && err == nil {
+				// an unlimited (non-positive) active deadline is replaced by the namespace maximum
+				if maximum, err := utils.ParseDurationAnnotation(nsMaximum); err == nil && (duration > maximum || (key == awv1beta2.ActiveDeadlineDurationAnnotation && duration <= 0 && maximum > 0)) {
+					warnings = append(warnings, fmt.Sprintf("%s: %v is limited to %v by namespace %v", key, duration, maximum, aw.Namespace))
+				}
+			}
+		case utils.LimitAnnotation:
+			if limit, err := utils.ParseLimitAnnotation(value); err != nil {
+				allErrors = append(allErrors, field.Invalid(path, value, err.Error()))
+			} else if nsMaximum, ok := policy[key+awv1beta2.MaximumAnnotationSuffix]; ok {
+				if maximum, err := utils.ParseLimitAnnotation(nsMaximum); err == nil && limit > maximum {
+					warnings = append(warnings, fmt.Sprintf("%s: %v is limited to %v by namespace %v", key, limit, maximum, aw.Namespace))
+				}
+			}
+		case utils.ExitCodesAnnotation:
+			if _, err := utils.ParseExitCodesAnnotation(value); err != nil {
+				allErrors = append(allErrors, field.Invalid(path, value, err.Error()))
+			}
+		case utils.StringAnnotation:
+			if value == "" {
+				allErrors = append(allErrors, field.Invalid(path, value, "must not be empty"))
+			}
+		case utils.BoolAnnotation:
+			if _, err := utils.ParseBoolAnnotation(value); err != nil {
+				allErrors = append(allErrors, field.Invalid(path, value, err.Error()))
+			}
+		}
+	}
+
+	return allErrors, warnings
+}
+
+// namespacePolicy returns the annotations of namespace, which supply maximums for the fault tolerance annotations
+// of its AppWrappers. It returns nil if namespace policies are disabled or the namespace cannot be read.
+func (w *appWrapperWebhook) namespacePolicy(ctx context.Context, namespace string) map[string]string {
+	if !w.config.Get().NamespacePolicies || w.client == nil || namespace == "" {
+		return nil
+	}
+	ns := &v1.Namespace{}
+	if err := w.client.Get(ctx, types.NamespacedName{Name: namespace}, ns); err != nil {
+		log.FromContext(ctx).V(2).Info("Unable to read namespace policy", "namespace", namespace, "error", err)
+		return nil
+	}
+	return ns.Annotations
+}
+
+// riskWarnings identifies legal AppWrappers with properties that frequently cause operational problems:
+//  1. AppWrappers without a queue name when no default queue is configured
+//  2. Declared PodSets for kinds whose PodSets cannot be inferred, and therefore whose replica counts cannot be validated
+//  3. Containers with no resource requests
+//  4. Containers using :latest images
+//  5. PodSets requesting a resource monitored by Autopilot that will avoid the nodes with the corresponding Autopilot taints
+//     because they do not tolerate them (only when the controller does not inject anti-affinities that avoid those nodes)
+func (w *appWrapperWebhook) riskWarnings(ctx context.Context, aw *awv1beta2.AppWrapper) admission.Warnings {
+	warnings := admission.Warnings{}
+	awConfig := w.config.Get()
+
+	// 1. Missing queue name
+	if aw.Labels[QueueNameLabel] == "" && awConfig.DefaultQueueName == "" {
+		warnings = append(warnings, fmt.Sprintf("AppWrapper has no %s label and no default queue is configured", QueueNameLabel))
+	}
+
+	for idx, component := range aw.Spec.Components {
+		unstruct := &unstructured.Unstructured{}
+		if _, _, err := unstructured.UnstructuredJSONScheme.Decode(component.Template.Raw, nil, unstruct); err != nil {
+			continue // reported by validateAppWrapperCreate
+		}
+		podSets := component.DeclaredPodSets
+		if inferred, err := utils.InferPodSets(unstruct); err == nil && len(inferred) > 0 {
+			podSets = inferred
+		} else if len(component.DeclaredPodSets) > 0 {
+			// 2. Declared PodSets for an unknown kind
+			warnings = append(warnings, fmt.Sprintf("component %v: replica counts of the PodSets declared for kind %v cannot be validated",
+				idx, unstruct.GroupVersionKind().Kind))
+		}
+
+		for _, podSet := range podSets {
+			template, err := utils.GetPodTemplateSpec(unstruct, podSet.Path)
+			if err != nil {
+				continue // reported by validateAppWrapperCreate
+			}
+			where := fmt.Sprintf("component %v podSet %v", idx, podSet.Path)
+			requested := sets.New[v1.ResourceName]()
+			for _, container := range template.Spec.Containers {
+				// 3. No resource requests
+				if len(container.Resources.Requests) == 0 && len(container.Resources.Limits) == 0 {
+					warnings = append(warnings, fmt.Sprintf("%v: container %v has no resource requests", where, container.Name))
+				}
+				// 4. :latest images
+				if utils.GetImageTag(container.Image) == "latest" {
+					warnings = append(warnings, fmt.Sprintf("%v: container %v uses image %v with the latest tag", where, container.Name, container.Image))
+				}
+				requested.Insert(slices.Collect(maps.Keys(container.Resources.Requests))...)
+				requested.Insert(slices.Collect(maps.Keys(container.Resources.Limits))...)
+			}
+
+			// 5. Autopilot taints that are not tolerated
+			if awConfig.Autopilot != nil && !awConfig.Autopilot.InjectAntiAffinities {
+				for _, resource := range slices.Sorted(maps.Keys(awConfig.Autopilot.ResourceTaints)) {
+					if !requested.Has(v1.ResourceName(resource)) {
+						continue
+					}
+					for _, taint := range awConfig.Autopilot.ResourceTaints[resource] {
+						if taint.Effect != v1.TaintEffectNoSchedule && taint.Effect != v1.TaintEffectNoExecute {
+							continue
+						}
+						if !slices.ContainsFunc(template.Spec.Tolerations, func(t v1.Toleration) bool { return t.ToleratesTaint(log.FromContext(ctx), &taint, false) }) {
+							warnings = append(warnings, fmt.Sprintf("%v: requests %v and will avoid nodes with the Autopilot taint %v", where, resource, taint.ToString()))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return warnings
+}
+
+// compiledAdmissionPolicies returns the programs for the AdmissionPolicies of the current configuration,
+// compiling them if the configuration has changed since they were last compiled
+func (w *appWrapperWebhook) compiledAdmissionPolicies() ([]*policy.Program, error) {
+	awConfig := w.config.Get()
+	if cached := w.policies.Load(); cached != nil && cached.config == awConfig {
+		return cached.programs, nil
+	}
+	programs := make([]*policy.Program, len(awConfig.AdmissionPolicies))
+	for idx, ap := range awConfig.AdmissionPolicies {
+		program, err := policy.Compile(ap.Target, ap.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("admission policy %v: %w", ap.Name, err)
+		}
+		programs[idx] = program
+	}
+	w.policies.Store(&compiledPolicies{config: awConfig, programs: programs})
+	return programs, nil
+}
+
+// evaluatePolicies evaluates the configured AdmissionPolicies against aw, each of its components, and each of its PodTemplateSpecs.
+// Failed Deny policies produce errors; failed Warn policies produce warnings.
+func (w *appWrapperWebhook) evaluatePolicies(ctx context.Context, aw *awv1beta2.AppWrapper) (field.ErrorList, admission.Warnings) {
+	allErrors := field.ErrorList{}
+	warnings := admission.Warnings{}
+	admissionPolicies := w.config.Get().AdmissionPolicies
+	if len(admissionPolicies) == 0 {
+		return allErrors, warnings
+	}
+	componentsPath := field.NewPath("spec").Child("components")
+	programs, err := w.compiledAdmissionPolicies()
+	if err != nil {
+		return field.ErrorList{field.InternalError(componentsPath, err)}, warnings
+	}
+
+	awObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(aw)
+	if err != nil {
+		return field.ErrorList{field.InternalError(field.NewPath("spec"), err)}, warnings
+	}
+	components := make([]map[string]interface{}, len(aw.Spec.Components))
+	for idx, component := range aw.Spec.Components {
+		unstruct := &unstructured.Unstructured{}
+		if _, _, err := unstructured.UnstructuredJSONScheme.Decode(component.Template.Raw, nil, unstruct); err != nil {
+			return field.ErrorList{field.InternalError(componentsPath.Index(idx), err)}, warnings
+		}
+		components[idx] = unstruct.Object
+	}
+	templates, podSets, err := utils.GetComponentPodTemplates(aw.DeepCopy())
+	if err != nil {
+		return field.ErrorList{field.InternalError(componentsPath, err)}, warnings
+	}
+	podSetVars := make([]interface{}, len(podSets))
+	for idx, podSet := range podSets {
+		podSetVars[idx] = map[string]interface{}{"path": podSet.Path, "replicas": int64(utils.Replicas(podSet)), "template": templates[idx]}
+	}
+
+	for idx, ap := range admissionPolicies {
+		check := func(path *field.Path, subject string, variables map[string]any) {
+			allowed, err := programs[idx].Eval(variables)
+			if allowed {
+				return
+			}
+			msg := ap.Message
+			if msg == "" {
+				msg = fmt.Sprintf("failed expression: %v", ap.Expression)
+			}
+			if err != nil {
+				log.FromContext(ctx).Info("Error evaluating admission policy", "policy", ap.Name, "error", err)
+				msg = fmt.Sprintf("%v (evaluation error: %v)", msg, err)
+			}
+			msg = fmt.Sprintf("%vpolicy %v: %v", subject, ap.Name, msg)
+			if ap.Action == policy.ActionWarn {
+				warnings = append(warnings, fmt.Sprintf("%v: %v", path, msg))
+			} else {
+				allErrors = append(allErrors, field.Forbidden(path, msg))
+			}
+		}
+
+		switch ap.Target {
+		case policy.TargetAppWrapper:
+			check(field.NewPath("spec"), "", map[string]any{"object": awObj, "podSets": podSetVars})
+		case policy.TargetComponent:
+			for cIdx, component := range components {
+				check(componentsPath.Index(cIdx).Child("template"), "", map[string]any{"object": component, "appwrapper": awObj})
+			}
+		case policy.TargetPodTemplate:
+			for pIdx, podSet := range podSets {
+				check(componentsPath, fmt.Sprintf("podSet %v: ", podSet.Path), map[string]any{"object": templates[pIdx], "replicas": int64(utils.Replicas(podSet)), "appwrapper": awObj})
+			}
+		}
+	}
+
+	return allErrors, warnings
+}
+
+// componentsEditable returns true if the components of an AppWrapper may be changed by an update from old to new.
+// Components may be edited while the AppWrapper is suspended and has not been admitted by Kueue, and while
+// it is Running under this controller, which then restarts it to deploy the edited components.
+// Like the controller, the webhook attributes AppWrappers without managedBy to the default controller.
+func (w *appWrapperWebhook) componentsEditable(old *awv1beta2.AppWrapper, new *awv1beta2.AppWrapper) bool {
+	switch old.Status.Phase {
+	case awv1beta2.AppWrapperEmpty, awv1beta2.AppWrapperSuspended:
+		return old.Spec.Suspend && new.Spec.Suspend && !meta.IsStatusConditionTrue(old.Status.Conditions, string(awv1beta2.QuotaReserved))
+	case awv1beta2.AppWrapperRunning:
+		return !old.Spec.Suspend && !new.Spec.Suspend && ptr.Deref(old.Spec.ManagedBy, awv1beta2.AppWrapperControllerName) == w.controllerName
+	default:
+		return false
+	}
+}
+
+// quotaSpec returns the parts of a PodSpec on which the quota Kueue reserves for it and the placement of its Pods depend
+func quotaSpec(template *v1.PodTemplateSpec) v1.PodSpec {
+	resources := func(containers []v1.Container) []v1.Container {
+		ans := make([]v1.Container, len(containers))
+		for i := range containers {
+			ans[i].Resources = containers[i].Resources
+		}
+		return ans
+	}
+	return v1.PodSpec{
+		InitContainers:    resources(template.Spec.InitContainers),
+		Containers:        resources(template.Spec.Containers),
+		NodeSelector:      template.Spec.NodeSelector,
+		Tolerations:       template.Spec.Tolerations,
+		Affinity:          template.Spec.Affinity,
+		PriorityClassName: template.Spec.PriorityClassName,
+	}
+}
+
+// validatePodSetsUnchanged ensures that an edit does not change the quota and placement of the PodSets Kueue admitted
+// a Running AppWrapper with. Other edits, for example of container images, are permitted.
+// Edits that change the quota required by the AppWrapper must be made while it is suspended.
+func validatePodSetsUnchanged(old *awv1beta2.AppWrapper, new *awv1beta2.AppWrapper) field.ErrorList {
+	componentsPath := field.NewPath("spec").Child("components")
+	podSpecs := func(aw *awv1beta2.AppWrapper) ([]*v1.PodTemplateSpec, []awv1beta2.AppWrapperPodSet, error) {
+		fresh := &awv1beta2.AppWrapper{ObjectMeta: aw.ObjectMeta, Spec: *aw.Spec.DeepCopy()}
+		return utils.GetComponentPodSpecs(fresh)
+	}
+	oldTemplates, oldPodSets, err := podSpecs(old)
+	if err != nil {
+		return field.ErrorList{field.InternalError(componentsPath, err)}
+	}
+	newTemplates, newPodSets, err := podSpecs(new)
+	if err != nil {
+		return field.ErrorList{field.Invalid(componentsPath, new.Spec.Components, fmt.Sprintf("error computing PodSets: %v", err))}
+	}
+	if len(oldPodSets) != len(newPodSets) ||
+		!slices.EqualFunc(oldTemplates, newTemplates, func(a, b *v1.PodTemplateSpec) bool { return equality.Semantic.DeepEqual(quotaSpec(a), quotaSpec(b)) }) ||
+		!slices.EqualFunc(oldPodSets, newPodSets, func(a, b awv1beta2.AppWrapperPodSet) bool { return utils.Replicas(a) == utils.Replicas(b) }) {
+		return field.ErrorList{field.Forbidden(componentsPath, "edits to a running AppWrapper must not change its PodSets; suspend it first")}
+	}
+	return nil
+}
+
+// validateAppWrapperUpdate enforces deep immutablity of all fields that were validated by validateAppWrapperCreate.
+// The components are exempt while componentsEditable; ValidateUpdate then revalidates them with validateAppWrapperCreate.
 func (w *appWrapperWebhook) validateAppWrapperUpdate(old *awv1beta2.AppWrapper, new *awv1beta2.AppWrapper) field.ErrorList {
+	allErrors := field.ErrorList{}
+	msg := "attempt to change immutable field"
+	if !w.componentsEditable(old, new) {
+		allErrors = append(allErrors, validateComponentsUnchanged(old, new)...)
+	} else if old.Status.Phase == awv1beta2.AppWrapperRunning {
+		allErrors = append(allErrors, validatePodSetsUnchanged(old, new)...)
+	}
+
+	// ensure user name and id are not mutated
+	if old.Labels[AppWrapperUsernameLabel] != new.Labels[AppWrapperUsernameLabel] {
+		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("labels").Key(AppWrapperUsernameLabel), msg))
+	}
+	if old.Labels[AppWrapperUserIDLabel] != new.Labels[AppWrapperUserIDLabel] {
+		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("labels").Key(AppWrapperUserIDLabel), msg))
+	}
+	if old.Annotations[awv1beta2.AppWrapperUserInfoAnnotation] != new.Annotations[awv1beta2.AppWrapperUserInfoAnnotation] {
+		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("annotations").Key(awv1beta2.AppWrapperUserInfoAnnotation), msg))
+	}
+	if utils.MergeIdenticalPodSets(old) != utils.MergeIdenticalPodSets(new) {
+		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("annotations").Key(awv1beta2.MergeIdenticalPodSetsAnnotation), msg))
+	}
+
+	// ensure managedBy field is immutable
+	if !ptr.Equal(old.Spec.ManagedBy, new.Spec.ManagedBy) {
+		allErrors = append(allErrors, field.Forbidden(field.NewPath("spec").Child("managedBy"), msg))
+	}
+
+	return allErrors
+}
+
+// validateComponentsUnchanged enforces deep immutability of the templates and PodSets of an AppWrapper's components
+func validateComponentsUnchanged(old *awv1beta2.AppWrapper, new *awv1beta2.AppWrapper) field.ErrorList {
 	allErrors := field.ErrorList{}
 	msg := "attempt to change immutable field"
 	componentsPath := field.NewPath("spec").Child("components")
@@ -259,63 +737,247 @@ func (w *appWrapperWebhook) validateAppWrapperUpdate(old *awv1beta2.AppWrapper,
 			}
 		}
 	}
+	return allErrors
+}
 
-	// ensure user name and id are not mutated
-	if old.Labels[AppWrapperUsernameLabel] != new.Labels[AppWrapperUsernameLabel] {
-		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("labels").Key(AppWrapperUsernameLabel), msg))
+// userAuthorized performs a SubjectAccessReview to determine if the user is permitted to perform the action described by ra.
+// Results are cached for the configured UserRBACCacheTTL to reduce the load on the API server from bulk submissions.
+func (w *appWrapperWebhook) userAuthorized(ctx context.Context, userInfo authenticationv1.UserInfo, ra *authv1.ResourceAttributes) (bool, error) {
+	ttl := w.config.Get().UserRBACCacheTTL
+	key := sarCacheKey(userInfo, ra)
+	if ttl > 0 {
+		if allowed, ok := w.rbacACSupport.cachedSAR(key); ok {
+			metrics.SubjectAccessReviewCacheLookups.WithLabelValues("hit").Inc()
+			return allowed, nil
+		}
+		metrics.SubjectAccessReviewCacheLookups.WithLabelValues("miss").Inc()
 	}
-	if old.Labels[AppWrapperUserIDLabel] != new.Labels[AppWrapperUserIDLabel] {
-		allErrors = append(allErrors, field.Forbidden(field.NewPath("metadata").Child("labels").Key(AppWrapperUserIDLabel), msg))
+
+	sar := &authv1.SubjectAccessReview{
+		Spec: authv1.SubjectAccessReviewSpec{
+			ResourceAttributes: ra,
+			User:               userInfo.Username,
+			UID:                userInfo.UID,
+			Groups:             userInfo.Groups,
+		}}
+	if len(userInfo.Extra) > 0 {
+		sar.Spec.Extra = make(map[string]authv1.ExtraValue, len(userInfo.Extra))
+		for k, v := range userInfo.Extra {
+			sar.Spec.Extra[k] = authv1.ExtraValue(v)
+		}
+	}
+	start := time.Now()
+	sar, err := w.rbacACSupport.subjectAccessReviewer.Create(ctx, sar, metav1.CreateOptions{})
+	metrics.SubjectAccessReviewDuration.WithLabelValues("SubjectAccessReview").Observe(time.Since(start).Seconds())
+	if err != nil {
+		return false, err
 	}
+	if ttl > 0 {
+		w.rbacACSupport.cacheSAR(key, sar.Status.Allowed, ttl)
+	}
+	return sar.Status.Allowed, nil
+}
 
-	// ensure managedBy field is immutable
-	if !ptr.Equal(old.Spec.ManagedBy, new.Spec.ManagedBy) {
-		allErrors = append(allErrors, field.Forbidden(field.NewPath("spec").Child("managedBy"), msg))
+// sarCacheKey identifies a SubjectAccessReview by the user's identity and the attributes of the reviewed action
+func sarCacheKey(userInfo authenticationv1.UserInfo, ra *authv1.ResourceAttributes) string {
+	groups := slices.Sorted(slices.Values(userInfo.Groups))
+	// json.Marshal sorts the keys of userInfo.Extra, so equal requests produce equal keys
+	key, _ := json.Marshal([]any{userInfo.Username, userInfo.UID, groups, userInfo.Extra, ra})
+	return string(key)
+}
+
+// cachedSAR returns the cached result of the SubjectAccessReview identified by key, if present and not expired
+func (r *rbacACSupport) cachedSAR(key string) (allowed bool, ok bool) {
+	r.sarCacheMutex.Lock()
+	defer r.sarCacheMutex.Unlock()
+	entry, ok := r.sarCache[key]
+	if !ok || time.Now().After(entry.expires) {
+		return false, false
 	}
+	return entry.allowed, true
+}
 
-	return allErrors
+// cacheSAR caches the result of the SubjectAccessReview identified by key for ttl
+func (r *rbacACSupport) cacheSAR(key string, allowed bool, ttl time.Duration) {
+	r.sarCacheMutex.Lock()
+	defer r.sarCacheMutex.Unlock()
+	now := time.Now()
+	if len(r.sarCache) >= sarCacheSweepSize {
+		maps.DeleteFunc(r.sarCache, func(_ string, entry sarCacheEntry) bool { return now.After(entry.expires) })
+	}
+	r.sarCache[key] = sarCacheEntry{allowed: allowed, expires: now.Add(ttl)}
 }
 
-func (w *appWrapperWebhook) lookupResource(gvk *schema.GroupVersionKind) string {
+// transitiveRBACChecks extends the userRBACAdmissionCheck to the resources that the components
+// of aw create indirectly by checking the user's permissions against every PodTemplateSpec:
+//  1. Creating Pods in the AppWrapper's namespace
+//  2. Using the referenced serviceAccountName
+//  3. Using the referenced priorityClassName
+func (w *appWrapperWebhook) transitiveRBACChecks(ctx context.Context, aw *awv1beta2.AppWrapper) field.ErrorList {
+	checks := w.config.Get().TransitiveRBAC
+	if !w.userRBACAdmissionCheck || checks == nil {
+		return nil
+	}
+	componentsPath := field.NewPath("spec").Child("components")
+	request, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return field.ErrorList{field.InternalError(componentsPath, err)}
+	}
+	// NOTE: use the raw templates; GetComponentPodSpecs only retains the fields that are relevant to Kueue
+	templates, _, err := utils.GetComponentPodTemplates(aw.DeepCopy())
+	if err != nil {
+		return field.ErrorList{field.InternalError(componentsPath, err)}
+	}
+
+	required := []authv1.ResourceAttributes{}
+	if checks.CreatePods && len(templates) > 0 {
+		required = append(required, authv1.ResourceAttributes{Namespace: aw.Namespace, Verb: "create", Version: "v1", Resource: "pods"})
+	}
+	serviceAccounts := sets.New[string]()
+	priorityClasses := sets.New[string]()
+	for _, template := range templates {
+		serviceAccount, _, _ := unstructured.NestedString(template, "spec", "serviceAccountName")
+		if serviceAccount == "" {
+			serviceAccount, _, _ = unstructured.NestedString(template, "spec", "serviceAccount") // deprecated alias
+		}
+		if checks.UseServiceAccounts && serviceAccount != "" && serviceAccount != "default" {
+			serviceAccounts.Insert(serviceAccount)
+		}
+		priorityClass, _, _ := unstructured.NestedString(template, "spec", "priorityClassName")
+		if checks.UsePriorityClasses && priorityClass != "" {
+			priorityClasses.Insert(priorityClass)
+		}
+	}
+	for _, name := range sets.List(serviceAccounts) {
+		required = append(required, authv1.ResourceAttributes{Namespace: aw.Namespace, Verb: "use", Version: "v1", Resource: "serviceaccounts", Name: name})
+	}
+	for _, name := range sets.List(priorityClasses) {
+		required = append(required, authv1.ResourceAttributes{Verb: "use", Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses", Name: name})
+	}
+
+	reviews := make([]func() field.ErrorList, len(required))
+	for i, ra := range required {
+		reviews[i] = func() field.ErrorList {
+			allowed, err := w.userAuthorized(ctx, request.UserInfo, &ra)
+			if err != nil {
+				return field.ErrorList{field.InternalError(componentsPath, err)}
+			} else if !allowed {
+				target := ra.Resource
+				if ra.Name != "" {
+					target = fmt.Sprintf("%v %v", ra.Resource, ra.Name)
+				}
+				where := ""
+				if ra.Namespace != "" {
+					where = " in " + ra.Namespace
+				}
+				reason := fmt.Sprintf("User %v is not authorized to %v %v%v", request.UserInfo.Username, ra.Verb, target, where)
+				return field.ErrorList{field.Forbidden(componentsPath, reason)}
+			}
+			return nil
+		}
+	}
+	return runConcurrently(reviews)
+}
+
+// preflightComponent verifies that the cluster serves gvk and that the controller's service account
+// is permitted to create it, so that a component that could never be created is rejected at admission
+// instead of failing with CreateFailed after the AdmissionGracePeriod. The permissions of the controller
+// are not checked if it impersonates the AppWrapper's creator (whose permissions the userRBACAdmissionCheck
+// verifies) or if its identity is not configured.
+func (w *appWrapperWebhook) preflightComponent(ctx context.Context, namespace string, gvk *schema.GroupVersionKind, path *field.Path) field.ErrorList {
+	resource, found, err := w.resolveResource(gvk)
+	if err != nil {
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+	if !found {
+		return field.ErrorList{field.Invalid(path.Child("kind"), gvk.Kind,
+			fmt.Sprintf("the cluster does not serve kind %v in %v; is its CustomResourceDefinition installed?", gvk.Kind, gvk.GroupVersion()))}
+	}
+	if w.impersonateUser || w.controllerUsername == "" {
+		return nil
+	}
+	ra := &authv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "create",
+		Group:     gvk.Group,
+		Version:   gvk.Version,
+		Resource:  resource,
+	}
+	allowed, err := w.userAuthorized(ctx, serviceAccountUserInfo(w.controllerUsername), ra)
+	if err != nil {
+		return field.ErrorList{field.InternalError(path, err)}
+	}
+	if !allowed {
+		reason := fmt.Sprintf("The AppWrapper controller is not authorized to create %v in %v; its RBAC permissions must be extended", resource, namespace)
+		return field.ErrorList{field.Forbidden(path, reason)}
+	}
+	return nil
+}
+
+// serviceAccountUserInfo returns the UserInfo of username including, if it is a service account, the groups it belongs to
+func serviceAccountUserInfo(username string) authenticationv1.UserInfo {
+	userInfo := authenticationv1.UserInfo{Username: username, Groups: []string{user.AllAuthenticated}}
+	if namespace, _, err := serviceaccount.SplitUsername(username); err == nil {
+		userInfo.Groups = append(serviceaccount.MakeGroupNames(namespace), user.AllAuthenticated)
+	}
+	return userInfo
+}
+
+// resolveResource uses discovery to map gvk to the name of its resource; found is false if the cluster does not serve gvk
+func (w *appWrapperWebhook) resolveResource(gvk *schema.GroupVersionKind) (resource string, found bool, err error) {
 	w.rbacACSupport.cacheMutex.RLock()
 	if known, ok := w.rbacACSupport.kindToResourceCache[gvk.String()]; ok {
 		w.rbacACSupport.cacheMutex.RUnlock()
-		return known
+		return known, true, nil
 	}
 	w.rbacACSupport.cacheMutex.RUnlock()
 	resources, err := w.rbacACSupport.discoveryClient.ServerResourcesForGroupVersion(gvk.GroupVersion().String())
 	if err != nil {
-		return "*"
+		if apierrors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
 	}
 	for _, r := range resources.APIResources {
-		if r.Kind == gvk.Kind {
+		if r.Kind == gvk.Kind && !strings.Contains(r.Name, "/") {
 			w.rbacACSupport.cacheMutex.Lock()
 			w.rbacACSupport.kindToResourceCache[gvk.String()] = r.Name
 			w.rbacACSupport.cacheMutex.Unlock()
-			return r.Name
+			return r.Name, true, nil
 		}
 	}
+	return "", false, nil
+}
+
+func (w *appWrapperWebhook) lookupResource(gvk *schema.GroupVersionKind) string {
+	if resource, found, err := w.resolveResource(gvk); err == nil && found {
+		return resource
+	}
 	return "*"
 }
 
-func SetupAppWrapperWebhook(mgr ctrl.Manager, awConfig *config.AppWrapperConfig) error {
+func SetupAppWrapperWebhook(mgr ctrl.Manager, sharedConfig *config.SharedAppWrapperConfig) error {
+	awConfig := sharedConfig.Get()
 	wh := &appWrapperWebhook{
 		client:                 mgr.GetClient(),
-		defaultQueueName:       awConfig.DefaultQueueName,
+		config:                 sharedConfig,
 		userRBACAdmissionCheck: awConfig.UserRBACAdmissionCheck,
+		controllerPreflight:    awConfig.ControllerPreflight,
+		controllerUsername:     awConfig.ControllerUsername,
+		impersonateUser:        awConfig.ImpersonateUser,
+		controllerName:         awConfig.ControllerName,
+		managedByAllowList:     awConfig.ManagedByAllowList,
+	}
+	if awConfig.Dispatcher != nil && awConfig.Dispatcher.Enabled {
+		wh.managedByAllowList = append(slices.Clone(wh.managedByAllowList), awConfig.Dispatcher.ControllerName)
 	}
 
-	if awConfig.UserRBACAdmissionCheck {
+	if awConfig.UserRBACAdmissionCheck || awConfig.ControllerPreflight {
 		kubeClient, err := kubernetes.NewForConfig(mgr.GetConfig())
 		if err != nil {
 			return err
 		}
-		wh.rbacACSupport = &rbacACSupport{
-			discoveryClient:       kubeClient.DiscoveryClient,
-			subjectAccessReviewer: kubeClient.AuthorizationV1().SubjectAccessReviews(),
-			kindToResourceCache:   make(map[string]string),
-		}
-
+		wh.rbacACSupport = newRBACACSupport(kubeClient)
 	}
 
 	return ctrl.NewWebhookManagedBy(mgr, &awv1beta2.AppWrapper{}).
@@ -17,14 +17,35 @@ limitations under the License.
 package webhook
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/internal/metrics"
 	utilmaps "github.com/project-codeflare/appwrapper/internal/util"
+	"github.com/project-codeflare/appwrapper/pkg/config"
+	"github.com/project-codeflare/appwrapper/pkg/policy"
+	"github.com/project-codeflare/appwrapper/pkg/utils"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -53,15 +74,267 @@ var _ = Describe("AppWrapper Webhook Tests", func() {
 		It("User name and ID are set", func() {
 			aw := toAppWrapper(pod(100))
 			aw.Labels = utilmaps.MergeKeepFirst(map[string]string{AppWrapperUsernameLabel: "bad", AppWrapperUserIDLabel: "bad"}, aw.Labels)
+			aw.Annotations = map[string]string{awv1beta2.AppWrapperUserInfoAnnotation: `{"username":"bad"}`}
 
 			Expect(k8sLimitedClient.Create(ctx, aw)).To(Succeed())
 			Expect(aw.Labels[AppWrapperUsernameLabel]).Should(BeIdenticalTo(limitedUserName))
 			Expect(aw.Labels[AppWrapperUserIDLabel]).Should(BeIdenticalTo(limitedUserID))
+			userInfo := authenticationv1.UserInfo{}
+			Expect(json.Unmarshal([]byte(aw.Annotations[awv1beta2.AppWrapperUserInfoAnnotation]), &userInfo)).To(Succeed())
+			Expect(userInfo.Username).Should(Equal(limitedUserName))
+			Expect(userInfo.UID).Should(Equal(limitedUserID))
+			Expect(userInfo.Groups).Should(ContainElement("system:authenticated"))
 			Expect(k8sLimitedClient.Delete(ctx, aw)).To(Succeed())
 		})
+
+		It("ManagedBy is set to the configured controller name", func() {
+			aw := toAppWrapper(pod(100))
+
+			Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+			Expect(aw.Spec.ManagedBy).Should(HaveValue(Equal(awv1beta2.AppWrapperControllerName)))
+			Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+		})
 	})
 
 	Context("Validating Webhook", func() {
+		It("ManagedBy must be in the allow-list", func() {
+			aw := toAppWrapper(pod(100))
+			aw.Spec.ManagedBy = ptr.To("example.com/unknown-controller")
+			Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
+
+			aw = toAppWrapper(pod(100))
+			aw.Spec.ManagedBy = ptr.To(config.MultiKueueControllerName)
+			Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+		})
+
+		Context("Annotations", func() {
+			It("Malformed annotations are rejected", func() {
+				aw := toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{awv1beta2.RetryLimitAnnotation: "three"}
+				Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
+
+				aw = toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{awv1beta2.WarmupGracePeriodDurationAnnotation: "5 min"}
+				Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
+
+				aw = toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{awv1beta2.TerminalExitCodesAnnotation: "3,x"}
+				Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
+
+			})
+
+			It("Unknown annotations produce warnings", func() {
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig())}
+				aw := toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{"workload.codeflare.dev.appwrapper/retrylimit": "3"}
+				errs, warnings := wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(ConsistOf(ContainSubstring("workload.codeflare.dev.appwrapper/retrylimit")))
+
+				Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+			})
+
+			It("Values that will be clamped by namespace maximums produce warnings", func() {
+				ns := &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: randName("policy"), Annotations: map[string]string{
+					awv1beta2.RetryLimitAnnotation + awv1beta2.MaximumAnnotationSuffix:                "2",
+					awv1beta2.WarmupGracePeriodDurationAnnotation + awv1beta2.MaximumAnnotationSuffix: "10m",
+					awv1beta2.ActiveDeadlineDurationAnnotation + awv1beta2.MaximumAnnotationSuffix:    "1h",
+				}}}
+				Expect(k8sClient.Create(ctx, ns)).To(Succeed())
+				defer func() { Expect(k8sClient.Delete(ctx, ns)).To(Succeed()) }()
+
+				awConfig := config.NewAppWrapperConfig()
+				wh := &appWrapperWebhook{client: k8sClient, config: config.NewSharedAppWrapperConfig(awConfig)}
+				aw := toAppWrapper(pod(100))
+				aw.Namespace = ns.Name
+				aw.Annotations = map[string]string{
+					awv1beta2.RetryLimitAnnotation:                 "3",
+					awv1beta2.WarmupGracePeriodDurationAnnotation:  "20m",
+					awv1beta2.ActiveDeadlineDurationAnnotation:     "0s",
+					awv1beta2.FailureGracePeriodDurationAnnotation: "1m",
+				}
+				errs, warnings := wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(ConsistOf(
+					ContainSubstring(awv1beta2.RetryLimitAnnotation),
+					ContainSubstring(awv1beta2.WarmupGracePeriodDurationAnnotation),
+					ContainSubstring(awv1beta2.ActiveDeadlineDurationAnnotation)))
+
+				awConfig = config.NewAppWrapperConfig()
+				awConfig.NamespacePolicies = false
+				wh.config.Set(awConfig)
+				errs, warnings = wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(BeEmpty())
+			})
+
+			It("Well-formed annotations are accepted and can be updated", func() {
+				aw := toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{
+					awv1beta2.RetryLimitAnnotation:                "3",
+					awv1beta2.WarmupGracePeriodDurationAnnotation: "5m",
+					awv1beta2.TerminalExitCodesAnnotation:         "3,10",
+					"example.com/unrelated":                       "anything",
+				}
+				awName := types.NamespacedName{Name: aw.Name, Namespace: aw.Namespace}
+				Expect(k8sClient.Create(ctx, aw)).To(Succeed())
+
+				aw = getAppWrapper(awName)
+				aw.Annotations[awv1beta2.RetryLimitAnnotation] = "many"
+				Expect(k8sClient.Update(ctx, aw)).ShouldNot(Succeed())
+
+				aw = getAppWrapper(awName)
+				aw.Annotations[awv1beta2.RetryLimitAnnotation] = "5"
+				Expect(k8sClient.Update(ctx, aw)).To(Succeed())
+				Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
+			})
+
+			It("Values that will be clamped produce warnings", func() {
+				awConfig := config.NewAppWrapperConfig()
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				aw := toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{
+					awv1beta2.WarmupGracePeriodDurationAnnotation:  (2 * awConfig.FaultTolerance.GracePeriodMaximum).String(),
+					awv1beta2.RetryPausePeriodDurationAnnotation:   "-1m",
+					awv1beta2.SuccessTTLAnnotation:                 (2 * awConfig.FaultTolerance.SuccessTTL).String(),
+					awv1beta2.FailureGracePeriodDurationAnnotation: "1m",
+				}
+				errs, warnings := wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(HaveLen(3))
+			})
+
+			It("Active deadlines are clamped to the ActiveDeadlineMaximum but not the GracePeriodMaximum", func() {
+				awConfig := config.NewAppWrapperConfig()
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				aw := toAppWrapper(pod(100))
+				aw.Annotations = map[string]string{
+					awv1beta2.ActiveDeadlineDurationAnnotation:  (2 * awConfig.FaultTolerance.GracePeriodMaximum).String(),
+					awv1beta2.SuspendOnActiveDeadlineAnnotation: "true",
+				}
+				errs, warnings := wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(BeEmpty())
+
+				awConfig.FaultTolerance.ActiveDeadlineMaximum = awConfig.FaultTolerance.GracePeriodMaximum
+				wh = &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				errs, warnings = wh.validateAnnotations(ctx, aw)
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(ConsistOf(ContainSubstring(awv1beta2.ActiveDeadlineDurationAnnotation)))
+			})
+		})
+
+		Context("Risk Warnings", func() {
+			var wh *appWrapperWebhook
+
+			BeforeEach(func() {
+				wh = &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig())}
+			})
+
+			It("Well-formed AppWrappers with a queue name have no warnings", func() {
+				aw := toAppWrapper(pod(100), deployment(2, 100))
+				aw.Labels = map[string]string{QueueNameLabel: userProvidedQueueName}
+				Expect(wh.riskWarnings(ctx, aw)).Should(BeEmpty())
+			})
+
+			It("Missing queue names produce a warning only if there is no default queue", func() {
+				aw := toAppWrapper(pod(100))
+				Expect(wh.riskWarnings(ctx, aw)).Should(ConsistOf(ContainSubstring(QueueNameLabel)))
+
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.DefaultQueueName = defaultQueueName
+				wh.config.Set(awConfig)
+				Expect(wh.riskWarnings(ctx, aw)).Should(BeEmpty())
+			})
+
+			It("Risky containers and PodSets produce warnings", func() {
+				aw := toAppWrapper(riskyPod())
+				aw.Labels = map[string]string{QueueNameLabel: userProvidedQueueName}
+				Expect(wh.riskWarnings(ctx, aw)).Should(ConsistOf(ContainSubstring("no resource requests"), ContainSubstring("latest tag")))
+
+				aw = toAppWrapper(gpuPod(1))
+				aw.Labels = map[string]string{QueueNameLabel: userProvidedQueueName}
+				Expect(wh.riskWarnings(ctx, aw)).Should(BeEmpty(), "the injected anti-affinities already avoid nodes with Autopilot taints")
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.Autopilot.InjectAntiAffinities = false
+				wh.config.Set(awConfig)
+				Expect(wh.riskWarnings(ctx, aw)).Should(ContainElement(ContainSubstring("will avoid nodes with the Autopilot taint")))
+
+				aw = toAppWrapper(unknownKind())
+				aw.Labels = map[string]string{QueueNameLabel: userProvidedQueueName}
+				Expect(wh.riskWarnings(ctx, aw)).Should(ConsistOf(ContainSubstring("cannot be validated")))
+			})
+		})
+
+		Context("Admission Policies", func() {
+			var wh *appWrapperWebhook
+
+			BeforeEach(func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.AdmissionPolicies = []config.AdmissionPolicy{
+					{
+						Name:       "no-host-path",
+						Target:     policy.TargetPodTemplate,
+						Expression: "!has(object.spec.volumes) || object.spec.volumes.all(v, !has(v.hostPath))",
+					},
+					{
+						Name:       "gpu-priority",
+						Target:     policy.TargetPodTemplate,
+						Expression: "!object.spec.containers.exists(c, has(c.resources.limits) && 'nvidia.com/gpu' in c.resources.limits) || has(object.spec.priorityClassName)",
+						Message:    "GPU pods must set priorityClassName",
+					},
+					{
+						Name:   "max-gpus",
+						Target: policy.TargetAppWrapper,
+						Expression: "podSets.map(p, p.replicas * p.template.spec.containers.map(c, has(c.resources.limits) && 'nvidia.com/gpu' in c.resources.limits ? " +
+							"quantity(string(c.resources.limits['nvidia.com/gpu'])).asInteger() : 0).sum()).sum() <= 4",
+						Action: policy.ActionWarn,
+					},
+					{
+						Name:       "no-deployments",
+						Target:     policy.TargetComponent,
+						Expression: "object.kind != 'Deployment' || appwrapper.metadata.name.startsWith('deploy-ok')",
+					},
+				}
+				Expect(config.ValidateAppWrapperConfig(awConfig)).To(Succeed())
+				wh = &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+			})
+
+			It("Compliant AppWrappers pass all policies", func() {
+				errs, warnings := wh.evaluatePolicies(ctx, toAppWrapper(pod(100), jobSet(2, 100)))
+				Expect(errs).Should(BeEmpty())
+				Expect(warnings).Should(BeEmpty())
+			})
+
+			It("Policies are evaluated against PodTemplates", func() {
+				errs, _ := wh.evaluatePolicies(ctx, toAppWrapper(gpuPod(1)))
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Detail).Should(ContainSubstring("GPU pods must set priorityClassName"))
+			})
+
+			It("Policies are evaluated against the AppWrapper", func() {
+				_, warnings := wh.evaluatePolicies(ctx, toAppWrapper(gpuPod(3), gpuPod(3)))
+				Expect(warnings).Should(ConsistOf(ContainSubstring("max-gpus")))
+			})
+
+			It("Policies are evaluated against Components", func() {
+				errs, _ := wh.evaluatePolicies(ctx, toAppWrapper(deployment(1, 100)))
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Detail).Should(ContainSubstring("no-deployments"))
+			})
+
+			It("Denied AppWrappers are rejected by the webhook", func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.AdmissionPolicies = []config.AdmissionPolicy{{Name: "cpu-only", Target: policy.TargetPodTemplate, Expression: "false"}}
+				Expect(config.ValidateAppWrapperConfig(awConfig)).To(Succeed())
+				wh.config.Set(awConfig)
+				_, err := wh.ValidateCreate(admission.NewContextWithRequest(ctx, admission.Request{}), toAppWrapper(pod(100)))
+				Expect(err).Should(HaveOccurred())
+			})
+		})
+
 		Context("Structural Invariants", func() {
 			It("There must be at least one podspec (a)", func() {
 				aw := toAppWrapper()
@@ -78,6 +351,52 @@ var _ = Describe("AppWrapper Webhook Tests", func() {
 				Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
 			})
 
+			It("The maximum number of podspecs is configurable", func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.MaxPodSets = 2
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				actx := admission.NewContextWithRequest(ctx, admission.Request{})
+				Expect(wh.validateAppWrapperCreate(actx, toAppWrapper(pod(100), pod(200)))).Should(BeEmpty())
+				Expect(wh.validateAppWrapperCreate(actx, toAppWrapper(pod(100), pod(200), pod(300)))).ShouldNot(BeEmpty())
+
+				awConfig.MaxPodSets = 16
+				aw := toAppWrapper(pod(100), pod(100), pod(100), pod(100), pod(100), pod(100), pod(100), pod(100), pod(100))
+				Expect(wh.validateAppWrapperCreate(actx, aw)).Should(BeEmpty())
+			})
+
+			It("Identical podspecs are counted once when they will be merged", func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.MaxPodSets = 2
+				awConfig.MergeIdenticalPodSets = true
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				actx := admission.NewContextWithRequest(ctx, admission.Request{})
+				aw := toAppWrapper(pod(100), pod(100), pod(100), pod(200))
+				Expect(wh.validateAppWrapperCreate(actx, aw)).ShouldNot(BeEmpty())
+
+				Expect(wh.Default(actx, aw)).To(Succeed())
+				Expect(aw.Annotations).Should(HaveKeyWithValue(awv1beta2.MergeIdenticalPodSetsAnnotation, "true"))
+				Expect(wh.validateAppWrapperCreate(actx, aw)).Should(BeEmpty())
+				_, podSets, err := utils.GetComponentPodSpecs(aw.DeepCopy())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(podSets).Should(HaveLen(2))
+				Expect(utils.Replicas(podSets[0])).Should(Equal(int32(3)))
+
+				aw.Annotations[awv1beta2.MergeIdenticalPodSetsAnnotation] = "false"
+				Expect(wh.validateAppWrapperCreate(actx, aw)).ShouldNot(BeEmpty())
+			})
+
+			It("Merged PodSetInfos are propagated to every merged PodSet", func() {
+				aw := toAppWrapper(pod(100), pod(200), pod(100))
+				aw.Annotations = map[string]string{awv1beta2.MergeIdenticalPodSetsAnnotation: "true"}
+				first := awv1beta2.AppWrapperPodSetInfo{Labels: map[string]string{"podset": "first"}}
+				second := awv1beta2.AppWrapperPodSetInfo{Labels: map[string]string{"podset": "second"}}
+				Expect(utils.SetPodSetInfos(aw, []awv1beta2.AppWrapperPodSetInfo{first, second, first})).ShouldNot(Succeed())
+				Expect(utils.SetPodSetInfos(aw, []awv1beta2.AppWrapperPodSetInfo{first, second})).To(Succeed())
+				Expect(aw.Spec.Components[0].PodSetInfos).Should(Equal([]awv1beta2.AppWrapperPodSetInfo{first}))
+				Expect(aw.Spec.Components[1].PodSetInfos).Should(Equal([]awv1beta2.AppWrapperPodSetInfo{second}))
+				Expect(aw.Spec.Components[2].PodSetInfos).Should(Equal([]awv1beta2.AppWrapperPodSetInfo{first}))
+			})
+
 			It("Non-existent PodSpec paths are rejected", func() {
 				comp := deployment(4, 100)
 				comp.DeclaredPodSets[0].Path = "template.spec.missing"
@@ -132,6 +451,244 @@ var _ = Describe("AppWrapper Webhook Tests", func() {
 			})
 		})
 
+		Context("Wrappable Kinds", func() {
+			It("Every kind may be wrapped unless an allow-list is configured", func() {
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig())}
+				admissionCtx := admission.NewContextWithRequest(ctx, admission.Request{})
+				Expect(wh.validateAppWrapperCreate(admissionCtx, toAppWrapper(unknownKind()))).Should(BeEmpty())
+			})
+
+			It("Kinds not on the configured allow-list or on the deny-list are rejected with a field error", func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.AllowedKinds = []config.WrappableKind{{Group: "*", Kind: "Pod"}, {Group: "*.x-k8s.io", Kind: "*"}, {Group: "apps", Kind: "Deployment"}}
+				awConfig.DeniedKinds = []config.WrappableKind{{Group: "apps", Kind: "*"}}
+				Expect(config.ValidateAppWrapperConfig(awConfig)).To(Succeed())
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(awConfig)}
+				admissionCtx := admission.NewContextWithRequest(ctx, admission.Request{})
+
+				Expect(wh.validateAppWrapperCreate(admissionCtx, toAppWrapper(pod(100), jobSet(2, 100)))).Should(BeEmpty())
+
+				errs := wh.validateAppWrapperCreate(admissionCtx, toAppWrapper(pod(100), service()))
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Type).Should(Equal(field.ErrorTypeNotSupported))
+				Expect(errs[0].Field).Should(Equal("spec.components[1].template.kind"))
+
+				errs = wh.validateAppWrapperCreate(admissionCtx, toAppWrapper(deployment(1, 100)))
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Type).Should(Equal(field.ErrorTypeForbidden))
+				Expect(errs[0].Field).Should(Equal("spec.components[0].template.kind"))
+			})
+		})
+
+		Context("Transitive RBAC checks", func() {
+			var wh *appWrapperWebhook
+			var limitedCtx context.Context
+
+			withPodSpec := func(component awv1beta2.AppWrapperComponent, key string, value string) awv1beta2.AppWrapperComponent {
+				obj := map[string]interface{}{}
+				Expect(json.Unmarshal(component.Template.Raw, &obj)).To(Succeed())
+				obj["spec"].(map[string]interface{})[key] = value
+				raw, err := json.Marshal(obj)
+				Expect(err).NotTo(HaveOccurred())
+				component.Template.Raw = raw
+				return component
+			}
+
+			BeforeEach(func() {
+				kubeClient, err := kubernetes.NewForConfig(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.TransitiveRBAC = &config.TransitiveRBACConfig{CreatePods: true, UseServiceAccounts: true, UsePriorityClasses: true}
+				wh = &appWrapperWebhook{
+					config:                 config.NewSharedAppWrapperConfig(awConfig),
+					userRBACAdmissionCheck: true,
+					rbacACSupport:          newRBACACSupport(kubeClient),
+				}
+				limitedCtx = admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: limitedUserName, UID: limitedUserID},
+				}})
+			})
+
+			It("PodTemplates without references only require permission to create pods", func() {
+				Expect(wh.transitiveRBACChecks(limitedCtx, toAppWrapper(pod(100)))).Should(BeEmpty())
+			})
+
+			It("Referenced service accounts and priority classes require permission to use them", func() {
+				aw := toAppWrapper(withPodSpec(pod(100), "serviceAccountName", "trainer"), withPodSpec(pod(100), "priorityClassName", "high"))
+				errs := wh.transitiveRBACChecks(limitedCtx, aw)
+				Expect(errs).Should(HaveLen(2))
+				Expect(errs[0].Type).Should(Equal(field.ErrorTypeForbidden))
+				Expect(errs[0].Detail).Should(ContainSubstring("serviceaccounts trainer"))
+				Expect(errs[1].Detail).Should(ContainSubstring("priorityclasses high"))
+
+				adminCtx := admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+					UserInfo: authenticationv1.UserInfo{Username: "transitive-admin", Groups: []string{"system:masters"}},
+				}})
+				Expect(wh.transitiveRBACChecks(adminCtx, aw)).Should(BeEmpty())
+			})
+
+			It("Only the configured checks are performed", func() {
+				awConfig := config.NewAppWrapperConfig()
+				awConfig.TransitiveRBAC = &config.TransitiveRBACConfig{CreatePods: true}
+				wh.config.Set(awConfig)
+				aw := toAppWrapper(withPodSpec(pod(100), "serviceAccountName", "trainer"), withPodSpec(pod(100), "priorityClassName", "high"))
+				Expect(wh.transitiveRBACChecks(limitedCtx, aw)).Should(BeEmpty())
+			})
+		})
+
+		It("SubjectAccessReview results are cached", func() {
+			kubeClient, err := kubernetes.NewForConfig(cfg)
+			Expect(err).NotTo(HaveOccurred())
+			awConfig := config.NewAppWrapperConfig()
+			wh := &appWrapperWebhook{
+				config:                 config.NewSharedAppWrapperConfig(awConfig),
+				userRBACAdmissionCheck: true,
+				rbacACSupport:          newRBACACSupport(kubeClient),
+			}
+			userInfo := authenticationv1.UserInfo{Username: limitedUserName, UID: limitedUserID, Groups: []string{"b", "a"}}
+			ra := authv1.ResourceAttributes{Namespace: "default", Verb: "create", Group: "apps", Version: "v1", Resource: "deployments"}
+			hits := metrics.SubjectAccessReviewCacheLookups.WithLabelValues("hit")
+			misses := metrics.SubjectAccessReviewCacheLookups.WithLabelValues("miss")
+
+			startHits, startMisses := testutil.ToFloat64(hits), testutil.ToFloat64(misses)
+			Expect(wh.userAuthorized(ctx, userInfo, &ra)).Should(BeFalse())
+			Expect(testutil.ToFloat64(misses)).Should(Equal(startMisses + 1))
+
+			userInfo.Groups = []string{"a", "b"} // order of groups does not matter
+			Expect(wh.userAuthorized(ctx, userInfo, &ra)).Should(BeFalse())
+			Expect(testutil.ToFloat64(hits)).Should(Equal(startHits + 1))
+
+			ra.Namespace = "other"
+			Expect(wh.userAuthorized(ctx, userInfo, &ra)).Should(BeFalse())
+			Expect(testutil.ToFloat64(misses)).Should(Equal(startMisses + 2))
+
+			awConfig = config.NewAppWrapperConfig()
+			awConfig.UserRBACCacheTTL = 0
+			wh.config.Set(awConfig)
+			Expect(wh.userAuthorized(ctx, userInfo, &ra)).Should(BeFalse())
+			Expect(testutil.ToFloat64(hits)).Should(Equal(startHits + 1))
+			Expect(testutil.ToFloat64(misses)).Should(Equal(startMisses + 2))
+		})
+
+		Context("Controller preflight", func() {
+			var wh *appWrapperWebhook
+			var admissionCtx context.Context
+
+			BeforeEach(func() {
+				// the webhook runs as an administrator while the controller runs as the limited user, which lacks RBAC for Deployments
+				kubeClient, err := kubernetes.NewForConfig(cfg)
+				Expect(err).NotTo(HaveOccurred())
+				awConfig := config.NewAppWrapperConfig()
+				wh = &appWrapperWebhook{
+					config:              config.NewSharedAppWrapperConfig(awConfig),
+					controllerPreflight: true,
+					controllerUsername:  limitedUserName,
+					controllerName:      awConfig.ControllerName,
+					rbacACSupport:       newRBACACSupport(kubeClient),
+				}
+				admissionCtx = admission.NewContextWithRequest(ctx, admission.Request{})
+			})
+
+			It("Components the controller can create are accepted", func() {
+				aw := toAppWrapper(pod(100))
+				aw.Spec.ManagedBy = ptr.To(wh.controllerName)
+				Expect(wh.validateAppWrapperCreate(admissionCtx, aw)).Should(BeEmpty())
+			})
+
+			It("Components the controller is not permitted to create are rejected", func() {
+				aw := toAppWrapper(deployment(1, 100))
+				aw.Spec.ManagedBy = ptr.To(wh.controllerName)
+				errs := wh.validateAppWrapperCreate(admissionCtx, aw)
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Type).Should(Equal(field.ErrorTypeForbidden))
+			})
+
+			It("The permissions of a controller that impersonates the AppWrapper's creator are not checked", func() {
+				aw := toAppWrapper(deployment(1, 100))
+				aw.Spec.ManagedBy = ptr.To(wh.controllerName)
+				wh.impersonateUser = true
+				Expect(wh.validateAppWrapperCreate(admissionCtx, aw)).Should(BeEmpty())
+			})
+
+			It("The UserInfo of a service account includes its groups", func() {
+				userInfo := serviceAccountUserInfo("system:serviceaccount:appwrapper-system:" + config.DefaultControllerServiceAccountName)
+				Expect(userInfo.Groups).Should(ConsistOf("system:serviceaccounts", "system:serviceaccounts:appwrapper-system", "system:authenticated"))
+				Expect(serviceAccountUserInfo(limitedUserName).Groups).Should(ConsistOf("system:authenticated"))
+			})
+
+			It("Components whose kind is not served by the cluster are rejected", func() {
+				aw := toAppWrapper(jobSet(2, 100))
+				aw.Spec.ManagedBy = ptr.To(wh.controllerName)
+				errs := wh.validateAppWrapperCreate(admissionCtx, aw)
+				Expect(errs).Should(HaveLen(1))
+				Expect(errs[0].Field).Should(Equal("spec.components[0].template.kind"))
+				Expect(errs[0].Detail).Should(ContainSubstring("CustomResourceDefinition"))
+			})
+
+			It("SetupAppWrapperWebhook runs the preflight check by default", func() {
+				awConfig := config.NewAppWrapperConfig()
+				Expect(awConfig.ControllerPreflight).Should(BeTrue())
+				awConfig.ControllerUsername = serviceaccount.MakeUsername("default", config.DefaultControllerServiceAccountName)
+				awConfig.NamespacePolicies = false // namespaces are read from the cache of a Manager that is never started
+
+				// validate sends an AdmissionReview from an administrator to the validating webhook of a new Manager
+				validate := func(aw *awv1beta2.AppWrapper) *admissionv1.AdmissionResponse {
+					mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: k8sClient.Scheme(), Metrics: metricsserver.Options{BindAddress: "0"}})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(SetupAppWrapperWebhook(mgr, config.NewSharedAppWrapperConfig(awConfig))).To(Succeed())
+					awBytes, err := json.Marshal(aw)
+					Expect(err).NotTo(HaveOccurred())
+					review := admissionv1.AdmissionReview{
+						TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+						Request: &admissionv1.AdmissionRequest{
+							UID:       types.UID(randName("review")),
+							Kind:      metav1.GroupVersionKind{Group: awv1beta2.GroupVersion.Group, Version: awv1beta2.GroupVersion.Version, Kind: awv1beta2.AppWrapperKind},
+							Resource:  metav1.GroupVersionResource{Group: awv1beta2.GroupVersion.Group, Version: awv1beta2.GroupVersion.Version, Resource: "appwrappers"},
+							Operation: admissionv1.Create,
+							Namespace: aw.Namespace,
+							Name:      aw.Name,
+							UserInfo:  authenticationv1.UserInfo{Username: "admin", Groups: []string{"system:masters", "system:authenticated"}},
+							Object:    runtime.RawExtension{Raw: awBytes},
+						},
+					}
+					body, err := json.Marshal(review)
+					Expect(err).NotTo(HaveOccurred())
+					request := httptest.NewRequest(http.MethodPost, "/validate-workload-codeflare-dev-v1beta2-appwrapper", bytes.NewReader(body))
+					request.Header.Set("Content-Type", "application/json")
+					recorder := httptest.NewRecorder()
+					mgr.GetWebhookServer().WebhookMux().ServeHTTP(recorder, request)
+					Expect(recorder.Code).Should(Equal(http.StatusOK))
+					review.Response = nil
+					Expect(json.Unmarshal(recorder.Body.Bytes(), &review)).To(Succeed())
+					Expect(review.Response).ShouldNot(BeNil())
+					return review.Response
+				}
+
+				aw := toAppWrapper(pod(100))
+				aw.Spec.ManagedBy = ptr.To(awConfig.ControllerName)
+				response := validate(aw)
+				Expect(response.Allowed).Should(BeFalse())
+				Expect(response.Result.Message).Should(ContainSubstring("The AppWrapper controller is not authorized to create pods"))
+
+				By("Granting the controller's service account permission to create Pods")
+				binding := &rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{Name: randName("controller"), Namespace: aw.Namespace},
+					Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: config.DefaultControllerServiceAccountName, Namespace: "default"}},
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "limited-role"},
+				}
+				Expect(k8sClient.Create(ctx, binding)).To(Succeed())
+				defer func() { Expect(k8sClient.Delete(ctx, binding)).To(Succeed()) }()
+				Expect(validate(aw).Allowed).Should(BeTrue())
+			})
+
+			It("AppWrappers managed by another controller are not checked", func() {
+				aw := toAppWrapper(jobSet(2, 100))
+				aw.Spec.ManagedBy = ptr.To(config.DispatcherControllerName)
+				wh.managedByAllowList = []string{config.DispatcherControllerName}
+				Expect(wh.validateAppWrapperCreate(admissionCtx, aw)).Should(BeEmpty())
+			})
+		})
+
 		It("Components in other namespaces are rejected", func() {
 			aw := toAppWrapper(namespacedPod("test", 100))
 			Expect(k8sClient.Create(ctx, aw)).ShouldNot(Succeed())
@@ -161,6 +718,10 @@ var _ = Describe("AppWrapper Webhook Tests", func() {
 			aw.Labels[AppWrapperUserIDLabel] = "bad"
 			Expect(k8sClient.Update(ctx, aw)).ShouldNot(Succeed())
 
+			aw = getAppWrapper(awName)
+			aw.Annotations[awv1beta2.AppWrapperUserInfoAnnotation] = `{"username":"bad"}`
+			Expect(k8sClient.Update(ctx, aw)).ShouldNot(Succeed())
+
 			Expect(k8sClient.Delete(ctx, aw)).To(Succeed())
 		})
 
@@ -218,6 +779,101 @@ var _ = Describe("AppWrapper Webhook Tests", func() {
 				aw.Spec.Components[0].DeclaredPodSets[0].Replicas = ptr.To(int32(12))
 				Expect(k8sClient.Update(ctx, aw)).ShouldNot(Succeed())
 			})
+
+			It("Components of a Suspended AppWrapper can be edited and are revalidated", func() {
+				aw := toAppWrapper(pod(100))
+				aw.Spec.Suspend = true
+				awName := types.NamespacedName{Name: aw.Name, Namespace: aw.Namespace}
+				Expect(k8sLimitedClient.Create(ctx, aw)).Should(Succeed())
+
+				aw = getAppWrapper(awName)
+				aw.Spec.Components = []awv1beta2.AppWrapperComponent{pod(200), pod(300)}
+				Expect(k8sLimitedClient.Update(ctx, aw)).Should(Succeed())
+
+				aw = getAppWrapper(awName)
+				aw.Spec.Components[0].DeclaredPodSets[0].Path = "bad"
+				Expect(k8sLimitedClient.Update(ctx, aw)).ShouldNot(Succeed())
+
+				aw = getAppWrapper(awName)
+				aw.Spec.Components = append(aw.Spec.Components, deployment(4, 100))
+				Expect(k8sLimitedClient.Update(ctx, aw)).ShouldNot(Succeed(), "Limited user should not be allowed to add Deployments")
+
+				aw = getAppWrapper(awName)
+				aw.Spec.Suspend = false
+				aw.Spec.Components = []awv1beta2.AppWrapperComponent{pod(100)}
+				Expect(k8sLimitedClient.Update(ctx, aw)).ShouldNot(Succeed())
+				Expect(k8sLimitedClient.Delete(ctx, aw)).To(Succeed())
+			})
+
+			It("Components cannot be edited once quota is reserved", func() {
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig())}
+				oldAW := toAppWrapper(pod(100))
+				oldAW.Spec.Suspend = true
+				oldAW.Status.Phase = awv1beta2.AppWrapperSuspended
+				newAW := oldAW.DeepCopy()
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(200)}
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).Should(BeEmpty())
+
+				oldAW.Status.Conditions = []metav1.Condition{{Type: string(awv1beta2.QuotaReserved), Status: metav1.ConditionTrue}}
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).ShouldNot(BeEmpty())
+			})
+
+			It("Components of a Running AppWrapper can be edited if its PodSets are unchanged", func() {
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()), controllerName: awv1beta2.AppWrapperControllerName}
+				oldAW := toAppWrapper(pod(100), service())
+				oldAW.Spec.ManagedBy = ptr.To(awv1beta2.AppWrapperControllerName)
+				oldAW.Status.Phase = awv1beta2.AppWrapperRunning
+				oldAW.Status.Conditions = []metav1.Condition{{Type: string(awv1beta2.QuotaReserved), Status: metav1.ConditionTrue}}
+
+				newAW := oldAW.DeepCopy()
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(100)}
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).Should(BeEmpty())
+
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(200)}
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).ShouldNot(BeEmpty())
+
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(100), pod(100)}
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).ShouldNot(BeEmpty())
+
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(100)}
+				newAW.Spec.Suspend = true
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).ShouldNot(BeEmpty())
+			})
+
+			It("Running AppWrappers without managedBy belong to the default controller", func() {
+				oldAW := toAppWrapper(pod(100))
+				oldAW.Spec.ManagedBy = nil
+				oldAW.Status.Phase = awv1beta2.AppWrapperRunning
+				oldAW.Status.Conditions = []metav1.Condition{{Type: string(awv1beta2.QuotaReserved), Status: metav1.ConditionTrue}}
+				newAW := oldAW.DeepCopy()
+				newAW.Spec.Components = []awv1beta2.AppWrapperComponent{pod(100), service()}
+
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()), controllerName: awv1beta2.AppWrapperControllerName}
+				Expect(wh.componentsEditable(oldAW, newAW)).Should(BeTrue())
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).Should(BeEmpty())
+
+				wh.controllerName = "example.com/another-controller"
+				Expect(wh.componentsEditable(oldAW, newAW)).Should(BeFalse(), "only the default controller restarts AppWrappers without managedBy")
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).ShouldNot(BeEmpty())
+			})
+
+			It("The container images of a Running AppWrapper can be edited", func() {
+				wh := &appWrapperWebhook{config: config.NewSharedAppWrapperConfig(config.NewAppWrapperConfig()), controllerName: awv1beta2.AppWrapperControllerName}
+				oldAW := toAppWrapper(pod(100))
+				oldAW.Spec.ManagedBy = ptr.To(awv1beta2.AppWrapperControllerName)
+				oldAW.Status.Phase = awv1beta2.AppWrapperRunning
+				oldAW.Status.Conditions = []metav1.Condition{{Type: string(awv1beta2.QuotaReserved), Status: metav1.ConditionTrue}}
+
+				newAW := oldAW.DeepCopy()
+				newAW.Spec.Components[0].Template.Raw = bytes.ReplaceAll(newAW.Spec.Components[0].Template.Raw, []byte("busybox:1.36"), []byte("busybox:1.37"))
+				Expect(newAW.Spec.Components[0].Template.Raw).ShouldNot(Equal(oldAW.Spec.Components[0].Template.Raw))
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).Should(BeEmpty())
+
+				By("AppWrappers deployed before managedBy was defaulted can also be edited")
+				oldAW.Spec.ManagedBy = nil
+				newAW.Spec.ManagedBy = nil
+				Expect(wh.validateAppWrapperUpdate(oldAW, newAW)).Should(BeEmpty())
+			})
 		})
 
 		Context("RBAC is enforced for wrapped resouces", func() {
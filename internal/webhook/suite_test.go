@@ -156,7 +156,8 @@ var _ = BeforeSuite(func() {
 
 	conf := config.NewAppWrapperConfig()
 	conf.DefaultQueueName = defaultQueueName // add default queue name
-	err = SetupAppWrapperWebhook(mgr, conf)
+	conf.ControllerPreflight = false         // the CRDs of most wrapped kinds are not installed in the test environment
+	err = SetupAppWrapperWebhook(mgr, config.NewSharedAppWrapperConfig(conf))
 	Expect(err).NotTo(HaveOccurred())
 
 	//+kubebuilder:scaffold:webhook
@@ -18,12 +18,30 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	"github.com/project-codeflare/appwrapper/pkg/policy"
 )
 
+// MultiKueueControllerName is the managedBy value used by Kueue for AppWrappers dispatched by MultiKueue
+const MultiKueueControllerName = "kueue.x-k8s.io/multikueue"
+
+// DispatcherControllerName is the default managedBy value of AppWrappers that are dispatched to worker clusters
+const DispatcherControllerName = "workload.codeflare.dev/appwrapper-dispatcher"
+
+// DefaultControllerServiceAccountName is the name of the service account of the controller in the provided deployment configurations
+const DefaultControllerServiceAccountName = "appwrapper-controller-manager"
+
 type OperatorConfig struct {
 	AppWrapper        *AppWrapperConfig        `json:"appwrapper,omitempty"`
 	CertManagement    *CertManagementConfig    `json:"certManagement,omitempty"`
@@ -34,9 +52,23 @@ type OperatorConfig struct {
 type AppWrapperConfig struct {
 	Autopilot              *AutopilotConfig      `json:"autopilot,omitempty"`
 	UserRBACAdmissionCheck bool                  `json:"userRBACAdmissionCheck,omitempty"`
+	UserRBACCacheTTL       time.Duration         `json:"userRBACCacheTTL,omitempty"`
+	ControllerPreflight    bool                  `json:"controllerPreflight,omitempty"`
+	ControllerUsername     string                `json:"controllerUsername,omitempty"`
+	ImpersonateUser        bool                  `json:"impersonateUser,omitempty"`
+	TransitiveRBAC         *TransitiveRBACConfig `json:"transitiveRBAC,omitempty"`
+	MaxPodSets             int32                 `json:"maxPodSets,omitempty"`
+	MergeIdenticalPodSets  bool                  `json:"mergeIdenticalPodSets,omitempty"`
 	FaultTolerance         *FaultToleranceConfig `json:"faultTolerance,omitempty"`
+	NamespacePolicies      bool                  `json:"namespacePolicies,omitempty"`
 	SchedulerName          string                `json:"schedulerName,omitempty"`
 	DefaultQueueName       string                `json:"defaultQueueName,omitempty"`
+	ControllerName         string                `json:"controllerName,omitempty"`
+	ManagedByAllowList     []string              `json:"managedByAllowList,omitempty"`
+	Dispatcher             *DispatcherConfig     `json:"dispatcher,omitempty"`
+	AdmissionPolicies      []AdmissionPolicy     `json:"admissionPolicies,omitempty"`
+	AllowedKinds           []WrappableKind       `json:"allowedKinds,omitempty"`
+	DeniedKinds            []WrappableKind       `json:"deniedKinds,omitempty"`
 }
 
 type AutopilotConfig struct {
@@ -46,6 +78,14 @@ type AutopilotConfig struct {
 	PreferNoScheduleWeight *int32                `json:"preferNoScheduleWeight,omitempty"`
 }
 
+// TransitiveRBACConfig selects the additional checks performed by the userRBACAdmissionCheck
+// against every PodTemplateSpec of an AppWrapper, covering resources its components create indirectly
+type TransitiveRBACConfig struct {
+	CreatePods         bool `json:"createPods,omitempty"`
+	UseServiceAccounts bool `json:"useServiceAccounts,omitempty"`
+	UsePriorityClasses bool `json:"usePriorityClasses,omitempty"`
+}
+
 type FaultToleranceConfig struct {
 	AdmissionGracePeriod        time.Duration `json:"admissionGracePeriod,omitempty"`
 	WarmupGracePeriod           time.Duration `json:"warmupGracePeriod,omitempty"`
@@ -53,8 +93,112 @@ type FaultToleranceConfig struct {
 	RetryPausePeriod            time.Duration `json:"resetPause,omitempty"`
 	RetryLimit                  int32         `json:"retryLimit,omitempty"`
 	ForcefulDeletionGracePeriod time.Duration `json:"deletionGracePeriod,omitempty"`
+	SuspensionGracePeriod       time.Duration `json:"suspensionGracePeriod,omitempty"`
 	GracePeriodMaximum          time.Duration `json:"gracePeriodCeiling,omitempty"`
 	SuccessTTL                  time.Duration `json:"successTTLCeiling,omitempty"`
+	ActiveDeadline              time.Duration `json:"activeDeadline,omitempty"`
+	ActiveDeadlineMaximum       time.Duration `json:"activeDeadlineCeiling,omitempty"`
+}
+
+type DispatcherConfig struct {
+	Enabled         bool                  `json:"enabled,omitempty"`
+	ControllerName  string                `json:"controllerName,omitempty"`
+	SecretNamespace string                `json:"secretNamespace,omitempty"`
+	WorkerClusters  []WorkerClusterConfig `json:"workerClusters,omitempty"`
+	SyncPeriod      time.Duration         `json:"syncPeriod,omitempty"`
+}
+
+type WorkerClusterConfig struct {
+	Name             string `json:"name"`
+	KubeconfigSecret string `json:"kubeconfigSecret"`
+}
+
+// WrappableKind identifies kinds of resources that may (or may not) be wrapped in an AppWrapper.
+// Group may be "*" to match every group or "*.<suffix>" to match every group ending in .<suffix>.
+// Kind may be "*" to match every kind. An empty Version matches every version.
+// Resource is the plural resource name of the kind; it is only used to generate the controller's RBAC rules.
+type WrappableKind struct {
+	Group    string `json:"group"`
+	Version  string `json:"version,omitempty"`
+	Kind     string `json:"kind"`
+	Resource string `json:"resource,omitempty"`
+}
+
+// DefaultWrappableKinds are the kinds the controller is granted permission to manage by its default ClusterRole.
+// The RBAC markers in internal/controller/appwrapper/zz_generated.rbac.go are generated from this list by make genrbac.
+// It does not restrict the kinds the webhook admits; AllowedKinds is empty (no restriction) by default.
+var DefaultWrappableKinds = []WrappableKind{
+	{Group: "", Kind: "Pod", Resource: "pods"},
+	{Group: "", Kind: "Service", Resource: "services"},
+	{Group: "apps", Kind: "Deployment", Resource: "deployments"},
+	{Group: "apps", Kind: "StatefulSet", Resource: "statefulsets"},
+	{Group: "batch", Kind: "Job", Resource: "jobs"},
+	{Group: "scheduling.sigs.k8s.io", Kind: "PodGroup", Resource: "podgroups"},
+	{Group: "scheduling.x-k8s.io", Kind: "PodGroup", Resource: "podgroups"},
+	{Group: "kubeflow.org", Kind: "PyTorchJob", Resource: "pytorchjobs"},
+	{Group: "ray.io", Kind: "RayCluster", Resource: "rayclusters"},
+	{Group: "ray.io", Kind: "RayJob", Resource: "rayjobs"},
+	{Group: "jobset.x-k8s.io", Kind: "JobSet", Resource: "jobsets"},
+}
+
+// Matches returns true if gvk is matched by wk
+func (wk WrappableKind) Matches(gvk schema.GroupVersionKind) bool {
+	groupMatches := wk.Group == "*" || wk.Group == gvk.Group ||
+		(strings.HasPrefix(wk.Group, "*.") && strings.HasSuffix(gvk.Group, wk.Group[1:]))
+	return groupMatches && (wk.Kind == "*" || wk.Kind == gvk.Kind) && (wk.Version == "" || wk.Version == gvk.Version)
+}
+
+// String formats wk as Kind.version.group, omitting empty parts
+func (wk WrappableKind) String() string {
+	return strings.Join(slices.DeleteFunc([]string{wk.Kind, wk.Version, wk.Group}, func(s string) bool { return s == "" }), ".")
+}
+
+// RBACMarkers returns the kubebuilder RBAC markers that grant the controller permission to manage kinds.
+// Resources of the same group are combined into a single marker; kinds without a Resource are skipped.
+func RBACMarkers(kinds []WrappableKind) []string {
+	groups := []string{}
+	resources := map[string][]string{}
+	for _, wk := range kinds {
+		if wk.Resource == "" {
+			continue
+		}
+		if _, ok := resources[wk.Group]; !ok {
+			groups = append(groups, wk.Group)
+		}
+		if !slices.Contains(resources[wk.Group], wk.Resource) {
+			resources[wk.Group] = append(resources[wk.Group], wk.Resource)
+		}
+	}
+	markers := make([]string, len(groups))
+	for i, group := range groups {
+		if group == "" {
+			group = `""`
+		}
+		markers[i] = fmt.Sprintf("//+kubebuilder:rbac:groups=%v,resources=%v,verbs=get;list;watch;create;delete",
+			group, strings.Join(resources[groups[i]], ";"))
+	}
+	return markers
+}
+
+// KindAllowed returns true if gvk may be wrapped: it must not match a DeniedKind and,
+// if AllowedKinds is not empty, it must match an AllowedKind
+func (config *AppWrapperConfig) KindAllowed(gvk schema.GroupVersionKind) bool {
+	matches := func(wk WrappableKind) bool { return wk.Matches(gvk) }
+	if slices.ContainsFunc(config.DeniedKinds, matches) {
+		return false
+	}
+	return len(config.AllowedKinds) == 0 || slices.ContainsFunc(config.AllowedKinds, matches)
+}
+
+// AdmissionPolicy is a CEL expression that the AppWrapper webhook evaluates when an AppWrapper is created.
+// Target selects what the expression is evaluated against (AppWrapper, Component, or PodTemplate).
+// If the expression does not evaluate to true, the AppWrapper is rejected (Action Deny) or a warning is returned (Action Warn).
+type AdmissionPolicy struct {
+	Name       string `json:"name"`
+	Target     string `json:"target"`
+	Expression string `json:"expression"`
+	Action     string `json:"action,omitempty"`
+	Message    string `json:"message,omitempty"`
 }
 
 type CertManagementConfig struct {
@@ -69,10 +213,13 @@ type CertManagementConfig struct {
 }
 
 type ControllerManagerConfig struct {
-	Metrics        MetricsConfiguration `json:"metrics,omitempty"`
-	Health         HealthConfiguration  `json:"health,omitempty"`
-	LeaderElection bool                 `json:"leaderElection,omitempty"`
-	EnableHTTP2    bool                 `json:"enableHTTP2,omitempty"`
+	Metrics           MetricsConfiguration  `json:"metrics,omitempty"`
+	Health            HealthConfiguration   `json:"health,omitempty"`
+	LeaderElection    bool                  `json:"leaderElection,omitempty"`
+	LeaderElectionID  string                `json:"leaderElectionID,omitempty"`
+	EnableHTTP2       bool                  `json:"enableHTTP2,omitempty"`
+	Namespaces        []string              `json:"namespaces,omitempty"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
 }
 
 type MetricsConfiguration struct {
@@ -98,6 +245,9 @@ func NewAppWrapperConfig() *AppWrapperConfig {
 			PreferNoScheduleWeight: ptr.To(int32(50)),
 		},
 		UserRBACAdmissionCheck: true,
+		UserRBACCacheTTL:       30 * time.Second,
+		MaxPodSets:             8,
+		ControllerPreflight:    true,
 		FaultTolerance: &FaultToleranceConfig{
 			AdmissionGracePeriod:        1 * time.Minute,
 			WarmupGracePeriod:           5 * time.Minute,
@@ -108,14 +258,32 @@ func NewAppWrapperConfig() *AppWrapperConfig {
 			GracePeriodMaximum:          24 * time.Hour,
 			SuccessTTL:                  7 * 24 * time.Hour,
 		},
+		NamespacePolicies:  true,
+		ControllerName:     awv1beta2.AppWrapperControllerName,
+		ManagedByAllowList: []string{MultiKueueControllerName},
+		Dispatcher: &DispatcherConfig{
+			Enabled:        false,
+			ControllerName: DispatcherControllerName,
+			SyncPeriod:     5 * time.Second,
+		},
 	}
 }
 
 func ValidateAppWrapperConfig(config *AppWrapperConfig) error {
+	if config.FaultTolerance == nil {
+		return fmt.Errorf("FaultTolerance must be specified")
+	}
+	if config.UserRBACCacheTTL < 0 {
+		return fmt.Errorf("UserRBACCacheTTL %v must not be negative", config.UserRBACCacheTTL)
+	}
 	if config.FaultTolerance.ForcefulDeletionGracePeriod > config.FaultTolerance.GracePeriodMaximum {
 		return fmt.Errorf("ForcefulDelectionGracePeriod %v exceeds GracePeriodCeiling %v",
 			config.FaultTolerance.ForcefulDeletionGracePeriod, config.FaultTolerance.GracePeriodMaximum)
 	}
+	if config.FaultTolerance.SuspensionGracePeriod > config.FaultTolerance.GracePeriodMaximum {
+		return fmt.Errorf("SuspensionGracePeriod %v exceeds GracePeriodCeiling %v",
+			config.FaultTolerance.SuspensionGracePeriod, config.FaultTolerance.GracePeriodMaximum)
+	}
 	if config.FaultTolerance.RetryPausePeriod > config.FaultTolerance.GracePeriodMaximum {
 		return fmt.Errorf("RetryPausePeriod %v exceeds GracePeriodCeiling %v",
 			config.FaultTolerance.RetryPausePeriod, config.FaultTolerance.GracePeriodMaximum)
@@ -139,7 +307,73 @@ func ValidateAppWrapperConfig(config *AppWrapperConfig) error {
 	if config.FaultTolerance.SuccessTTL <= 0 {
 		return fmt.Errorf("SuccessTTL %v is not a positive duration", config.FaultTolerance.SuccessTTL)
 	}
+	if config.FaultTolerance.ActiveDeadline < 0 || config.FaultTolerance.ActiveDeadlineMaximum < 0 {
+		return fmt.Errorf("ActiveDeadline %v and ActiveDeadlineCeiling %v must not be negative",
+			config.FaultTolerance.ActiveDeadline, config.FaultTolerance.ActiveDeadlineMaximum)
+	}
+	if maximum := config.FaultTolerance.ActiveDeadlineMaximum; maximum > 0 && config.FaultTolerance.ActiveDeadline > maximum {
+		return fmt.Errorf("ActiveDeadline %v exceeds ActiveDeadlineCeiling %v", config.FaultTolerance.ActiveDeadline, maximum)
+	}
+	if errs := validation.IsDomainPrefixedPath(field.NewPath("controllerName"), config.ControllerName); len(errs) > 0 {
+		return fmt.Errorf("invalid ControllerName: %w", errs.ToAggregate())
+	}
+	for idx, name := range config.ManagedByAllowList {
+		if errs := validation.IsDomainPrefixedPath(field.NewPath("managedByAllowList").Index(idx), name); len(errs) > 0 {
+			return fmt.Errorf("invalid ManagedByAllowList: %w", errs.ToAggregate())
+		}
+	}
+	if config.Dispatcher != nil && config.Dispatcher.Enabled {
+		if errs := validation.IsDomainPrefixedPath(field.NewPath("dispatcher").Child("controllerName"), config.Dispatcher.ControllerName); len(errs) > 0 {
+			return fmt.Errorf("invalid Dispatcher.ControllerName: %w", errs.ToAggregate())
+		}
+		if config.Dispatcher.ControllerName == config.ControllerName {
+			return fmt.Errorf("Dispatcher.ControllerName must differ from ControllerName %v", config.ControllerName)
+		}
+		if len(config.Dispatcher.WorkerClusters) == 0 {
+			return fmt.Errorf("Dispatcher is enabled but no WorkerClusters are configured")
+		}
+		names := map[string]bool{}
+		for _, wc := range config.Dispatcher.WorkerClusters {
+			if wc.Name == "" || wc.KubeconfigSecret == "" {
+				return fmt.Errorf("WorkerCluster %q must specify both a name and a kubeconfigSecret", wc.Name)
+			}
+			if names[wc.Name] {
+				return fmt.Errorf("WorkerCluster %q is defined more than once", wc.Name)
+			}
+			names[wc.Name] = true
+		}
+		if config.Dispatcher.SyncPeriod <= 0 {
+			return fmt.Errorf("Dispatcher.SyncPeriod %v is not a positive duration", config.Dispatcher.SyncPeriod)
+		}
+	}
+	policyNames := map[string]bool{}
+	for _, ap := range config.AdmissionPolicies {
+		if ap.Name == "" {
+			return fmt.Errorf("AdmissionPolicy with expression %q must specify a name", ap.Expression)
+		}
+		if policyNames[ap.Name] {
+			return fmt.Errorf("AdmissionPolicy %q is defined more than once", ap.Name)
+		}
+		policyNames[ap.Name] = true
+		if ap.Action != "" && ap.Action != policy.ActionDeny && ap.Action != policy.ActionWarn {
+			return fmt.Errorf("AdmissionPolicy %q has unknown action %q", ap.Name, ap.Action)
+		}
+		if _, err := policy.Compile(ap.Target, ap.Expression); err != nil {
+			return fmt.Errorf("AdmissionPolicy %q: %w", ap.Name, err)
+		}
+	}
+	for _, wk := range slices.Concat(config.AllowedKinds, config.DeniedKinds) {
+		if wk.Kind == "" {
+			return fmt.Errorf("WrappableKind %q must specify a kind", wk.String())
+		}
+		if strings.Contains(strings.TrimPrefix(wk.Group, "*"), "*") || (strings.HasPrefix(wk.Group, "*") && wk.Group != "*" && !strings.HasPrefix(wk.Group, "*.")) {
+			return fmt.Errorf("WrappableKind %q has an invalid group pattern %q", wk.String(), wk.Group)
+		}
+	}
 
+	if config.MaxPodSets < 1 {
+		return fmt.Errorf("MaxPodSets %v must be positive", config.MaxPodSets)
+	}
 	return nil
 }
 
@@ -166,7 +400,26 @@ func NewControllerManagerConfig() *ControllerManagerConfig {
 		Health: HealthConfiguration{
 			BindAddress: ":8081",
 		},
-		LeaderElection: false,
-		EnableHTTP2:    false,
+		LeaderElection:   false,
+		LeaderElectionID: "f134c674.codeflare.dev",
+		EnableHTTP2:      false,
 	}
 }
+
+// ValidateControllerManagerConfig checks the namespace restrictions of a ControllerManagerConfig
+func ValidateControllerManagerConfig(config *ControllerManagerConfig) error {
+	for _, ns := range config.Namespaces {
+		if errs := validation.IsDNS1123Label(ns); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace %q: %v", ns, errs)
+		}
+	}
+	if config.NamespaceSelector != nil {
+		if len(config.Namespaces) > 0 {
+			return fmt.Errorf("at most one of Namespaces and NamespaceSelector may be specified")
+		}
+		if _, err := metav1.LabelSelectorAsSelector(config.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid NamespaceSelector: %w", err)
+		}
+	}
+	return nil
+}
@@ -17,9 +17,14 @@ limitations under the License.
 package config
 
 import (
+	"os"
+	"strings"
 	"testing"
 	"time"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -41,9 +46,27 @@ var _ = Describe("AppWrapper Config", func() {
 		awc := NewAppWrapperConfig()
 		Expect(ValidateAppWrapperConfig(awc)).Should(Succeed())
 
+		awc.UserRBACCacheTTL = -1 * time.Second
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+		awc = NewAppWrapperConfig()
+
+		awc.MaxPodSets = 0
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+		awc = NewAppWrapperConfig()
+
+		awc.FaultTolerance.ActiveDeadline = 2 * time.Hour
+		awc.FaultTolerance.ActiveDeadlineMaximum = time.Hour
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+		awc.FaultTolerance.ActiveDeadline = 0
+		Expect(ValidateAppWrapperConfig(awc)).Should(Succeed())
+		awc = NewAppWrapperConfig()
+
 		bad := &FaultToleranceConfig{ForcefulDeletionGracePeriod: 10 * time.Second, GracePeriodMaximum: 1 * time.Second}
 		Expect(ValidateAppWrapperConfig(&AppWrapperConfig{FaultTolerance: bad})).ShouldNot(Succeed())
 
+		bad = &FaultToleranceConfig{SuspensionGracePeriod: 10 * time.Second, GracePeriodMaximum: 1 * time.Second}
+		Expect(ValidateAppWrapperConfig(&AppWrapperConfig{FaultTolerance: bad})).ShouldNot(Succeed())
+
 		bad = &FaultToleranceConfig{RetryPausePeriod: 10 * time.Second, GracePeriodMaximum: 1 * time.Second}
 		Expect(ValidateAppWrapperConfig(&AppWrapperConfig{FaultTolerance: bad})).ShouldNot(Succeed())
 
@@ -62,4 +85,104 @@ var _ = Describe("AppWrapper Config", func() {
 		bad = &FaultToleranceConfig{SuccessTTL: -1 * time.Second}
 		Expect(ValidateAppWrapperConfig(&AppWrapperConfig{FaultTolerance: bad})).ShouldNot(Succeed())
 	})
+
+	It("Reloadable Config", func() {
+		current := NewAppWrapperConfig()
+		update := NewAppWrapperConfig()
+		update.DefaultQueueName = "reloaded-queue"
+		update.FaultTolerance.RetryLimit = 7
+		update.Autopilot.ResourceTaints = map[string][]v1.Taint{}
+		merged, restartRequired := MergeReloadable(current, update)
+		Expect(restartRequired).Should(BeEmpty())
+		Expect(merged.DefaultQueueName).Should(Equal("reloaded-queue"))
+		Expect(merged.FaultTolerance.RetryLimit).Should(Equal(int32(7)))
+		Expect(merged.Autopilot.ResourceTaints).Should(BeEmpty())
+		Expect(current.DefaultQueueName).Should(BeEmpty())
+
+		update = NewAppWrapperConfig()
+		update.ControllerName = "example.com/other"
+		update.Autopilot.MonitorNodes = !current.Autopilot.MonitorNodes
+		update.ControllerPreflight = false
+		update.ImpersonateUser = true
+		update.ControllerUsername = "system:serviceaccount:other:controller"
+		merged, restartRequired = MergeReloadable(current, update)
+		Expect(restartRequired).Should(ConsistOf("controllerName", "autopilot.monitorNodes", "controllerPreflight", "impersonateUser", "controllerUsername"))
+		Expect(merged.ControllerName).Should(Equal(current.ControllerName))
+		Expect(merged.Autopilot.MonitorNodes).Should(Equal(current.Autopilot.MonitorNodes))
+		Expect(merged.ControllerUsername).Should(Equal(current.ControllerUsername))
+
+		current.ControllerUsername = "system:serviceaccount:appwrapper-system:appwrapper-controller-manager"
+		_, restartRequired = MergeReloadable(current, NewAppWrapperConfig())
+		Expect(restartRequired).Should(BeEmpty(), "a ConfigMap that omits controllerUsername keeps the default")
+	})
+
+	It("Config From ConfigMap", func() {
+		cfg := &OperatorConfig{AppWrapper: NewAppWrapperConfig()}
+		cm := &v1.ConfigMap{Data: map[string]string{"config.yaml": "appwrapper:\n  defaultQueueName: from-configmap\n"}}
+		Expect(ParseConfigMap(cm, cfg)).Should(Succeed())
+		Expect(cfg.AppWrapper.DefaultQueueName).Should(Equal("from-configmap"))
+		Expect(cfg.AppWrapper.FaultTolerance).Should(Equal(NewAppWrapperConfig().FaultTolerance))
+
+		Expect(ParseConfigMap(&v1.ConfigMap{}, cfg)).ShouldNot(Succeed())
+		Expect(ValidateAppWrapperConfig(&AppWrapperConfig{})).ShouldNot(Succeed())
+	})
+
+	It("Admission Policy Validation", func() {
+		awc := NewAppWrapperConfig()
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "ok", Target: "PodTemplate", Expression: "replicas <= 8", Action: "Warn"}}
+		Expect(ValidateAppWrapperConfig(awc)).Should(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "not-bool", Target: "PodTemplate", Expression: "replicas"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "bad-syntax", Target: "AppWrapper", Expression: "object.spec.("}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "wrong-variable", Target: "Component", Expression: "replicas <= 8"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "bad-target", Target: "Node", Expression: "true"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "bad-action", Target: "AppWrapper", Expression: "true", Action: "Ignore"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+
+		awc.AdmissionPolicies = []AdmissionPolicy{{Name: "dup", Target: "AppWrapper", Expression: "true"}, {Name: "dup", Target: "AppWrapper", Expression: "true"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+	})
+
+	It("Wrappable Kinds", func() {
+		awc := NewAppWrapperConfig()
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})).Should(BeTrue())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Widget"})).Should(BeTrue(), "every kind is allowed by default")
+
+		awc.AllowedKinds = []WrappableKind{{Group: "*.x-k8s.io", Kind: "*"}, {Group: "", Version: "v1", Kind: "Pod"}}
+		awc.DeniedKinds = []WrappableKind{{Group: "scheduling.x-k8s.io", Kind: "PodGroup"}}
+		Expect(ValidateAppWrapperConfig(awc)).Should(Succeed())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "jobset.x-k8s.io", Version: "v1alpha2", Kind: "JobSet"})).Should(BeTrue())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "scheduling.x-k8s.io", Version: "v1alpha1", Kind: "PodGroup"})).Should(BeFalse())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"})).Should(BeTrue())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "", Version: "v2", Kind: "Pod"})).Should(BeFalse())
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})).Should(BeFalse())
+
+		awc.AllowedKinds = nil
+		Expect(awc.KindAllowed(schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"})).Should(BeTrue())
+
+		awc.AllowedKinds = []WrappableKind{{Group: "batch"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+		awc.AllowedKinds = []WrappableKind{{Group: "x-k8s.*", Kind: "*"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+		awc.AllowedKinds = []WrappableKind{{Group: "*x-k8s.io", Kind: "*"}}
+		Expect(ValidateAppWrapperConfig(awc)).ShouldNot(Succeed())
+	})
+
+	It("Generated RBAC markers match the default wrappable kinds", func() {
+		generated, err := os.ReadFile("../../internal/controller/appwrapper/zz_generated.rbac.go")
+		Expect(err).NotTo(HaveOccurred())
+		markers := RBACMarkers(DefaultWrappableKinds)
+		for _, marker := range markers {
+			Expect(string(generated)).Should(ContainSubstring(marker+"\n"), "run make genrbac to regenerate RBAC markers")
+		}
+		Expect(strings.Count(string(generated), "//+kubebuilder:rbac:")).Should(Equal(len(markers)), "run make genrbac to regenerate RBAC markers")
+	})
 })
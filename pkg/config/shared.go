@@ -0,0 +1,134 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// SharedAppWrapperConfig gives concurrent readers access to the current AppWrapperConfig.
+// The AppWrapperConfig returned by Get must be treated as immutable; a reload replaces it as a whole.
+type SharedAppWrapperConfig struct {
+	current atomic.Pointer[AppWrapperConfig]
+}
+
+// NewSharedAppWrapperConfig constructs a SharedAppWrapperConfig whose initial value is config
+func NewSharedAppWrapperConfig(config *AppWrapperConfig) *SharedAppWrapperConfig {
+	shared := &SharedAppWrapperConfig{}
+	shared.current.Store(config)
+	return shared
+}
+
+// Get returns the current AppWrapperConfig
+func (s *SharedAppWrapperConfig) Get() *AppWrapperConfig {
+	return s.current.Load()
+}
+
+// Set atomically replaces the current AppWrapperConfig
+func (s *SharedAppWrapperConfig) Set(config *AppWrapperConfig) {
+	s.current.Store(config)
+}
+
+// MergeReloadable returns a copy of current in which the hot-reloadable fields are taken from update:
+// the Autopilot anti-affinity settings and ResourceTaints, FaultTolerance, SchedulerName, DefaultQueueName,
+// AdmissionPolicies, AllowedKinds, DeniedKinds, TransitiveRBAC, UserRBACCacheTTL, MaxPodSets and MergeIdenticalPodSets.
+// It also returns the names of the fields that differ between current and update but only take effect on restart.
+func MergeReloadable(current *AppWrapperConfig, update *AppWrapperConfig) (*AppWrapperConfig, []string) {
+	merged := *current
+	restartRequired := []string{}
+
+	if current.Autopilot != nil || update.Autopilot != nil {
+		autopilot, updated := AutopilotConfig{}, AutopilotConfig{}
+		if current.Autopilot != nil {
+			autopilot = *current.Autopilot
+		}
+		if update.Autopilot != nil {
+			updated = *update.Autopilot
+		}
+		if autopilot.MonitorNodes != updated.MonitorNodes {
+			restartRequired = append(restartRequired, "autopilot.monitorNodes")
+		}
+		autopilot.InjectAntiAffinities = updated.InjectAntiAffinities
+		autopilot.ResourceTaints = updated.ResourceTaints
+		autopilot.PreferNoScheduleWeight = updated.PreferNoScheduleWeight
+		merged.Autopilot = &autopilot
+	}
+	merged.FaultTolerance = update.FaultTolerance
+	merged.SchedulerName = update.SchedulerName
+	merged.DefaultQueueName = update.DefaultQueueName
+	merged.AdmissionPolicies = update.AdmissionPolicies
+	merged.AllowedKinds = update.AllowedKinds
+	merged.DeniedKinds = update.DeniedKinds
+	merged.TransitiveRBAC = update.TransitiveRBAC
+	merged.UserRBACCacheTTL = update.UserRBACCacheTTL
+	merged.MaxPodSets = update.MaxPodSets
+	merged.MergeIdenticalPodSets = update.MergeIdenticalPodSets
+
+	if current.UserRBACAdmissionCheck != update.UserRBACAdmissionCheck {
+		restartRequired = append(restartRequired, "userRBACAdmissionCheck")
+	}
+	if current.ControllerPreflight != update.ControllerPreflight {
+		restartRequired = append(restartRequired, "controllerPreflight")
+	}
+	if update.ControllerUsername != "" && current.ControllerUsername != update.ControllerUsername {
+		restartRequired = append(restartRequired, "controllerUsername")
+	}
+	if current.ImpersonateUser != update.ImpersonateUser {
+		restartRequired = append(restartRequired, "impersonateUser")
+	}
+	if current.NamespacePolicies != update.NamespacePolicies {
+		restartRequired = append(restartRequired, "namespacePolicies")
+	}
+	if current.ControllerName != update.ControllerName {
+		restartRequired = append(restartRequired, "controllerName")
+	}
+	if !reflect.DeepEqual(current.ManagedByAllowList, update.ManagedByAllowList) {
+		restartRequired = append(restartRequired, "managedByAllowList")
+	}
+	if current.Dispatcher != nil && update.Dispatcher != nil {
+		dispatcher := *update.Dispatcher
+		if dispatcher.SecretNamespace == "" {
+			dispatcher.SecretNamespace = current.Dispatcher.SecretNamespace
+		}
+		if !reflect.DeepEqual(*current.Dispatcher, dispatcher) {
+			restartRequired = append(restartRequired, "dispatcher")
+		}
+	} else if current.Dispatcher != update.Dispatcher {
+		restartRequired = append(restartRequired, "dispatcher")
+	}
+
+	return &merged, restartRequired
+}
+
+// ParseConfigMap unmarshals the operator configuration held by configMap into cfg.
+// Fields that are not present in configMap retain their values in cfg.
+func ParseConfigMap(configMap *v1.ConfigMap, cfg *OperatorConfig) error {
+	if len(configMap.Data) != 1 {
+		return fmt.Errorf("cannot resolve config from ConfigMap %s/%s", configMap.Namespace, configMap.Name)
+	}
+
+	for _, data := range configMap.Data {
+		return yaml.Unmarshal([]byte(data), cfg)
+	}
+
+	return nil
+}
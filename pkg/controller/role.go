@@ -0,0 +1,67 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import "fmt"
+
+// Role selects which parts of the AppWrapper operator a process runs
+type Role string
+
+const (
+	// RoleAll runs the webhooks and the controllers in a single process
+	RoleAll Role = "all"
+	// RoleWebhook runs only the webhooks (and their certificate management); it can be freely replicated
+	RoleWebhook Role = "webhook"
+	// RoleController runs only the controllers; replicas should use leader election
+	RoleController Role = "controller"
+)
+
+// ParseRole converts a command line argument into a Role
+func ParseRole(s string) (Role, error) {
+	switch r := Role(s); r {
+	case RoleAll, RoleWebhook, RoleController:
+		return r, nil
+	default:
+		return "", fmt.Errorf("unknown role %q: must be one of %v, %v, or %v", s, RoleAll, RoleWebhook, RoleController)
+	}
+}
+
+// RunsWebhooks returns true if processes with this Role serve the admission webhooks
+func (r Role) RunsWebhooks() bool {
+	return r == RoleAll || r == RoleWebhook
+}
+
+// RunsControllers returns true if processes with this Role run the controllers
+func (r Role) RunsControllers() bool {
+	return r == RoleAll || r == RoleController
+}
+
+// ServesWebhooks returns true if processes with this Role serve the admission webhooks
+// when webhooks are enabled (or not) by the operator configuration
+func (r Role) ServesWebhooks(webhooksEnabled bool) bool {
+	return webhooksEnabled && r.RunsWebhooks()
+}
+
+// ProbeRole returns the Role whose readiness check applies to processes with this Role.
+// A process that does not serve webhooks has no webhook server to wait for,
+// so it is probed like a controller-only process.
+func (r Role) ProbeRole(webhooksEnabled bool) Role {
+	if !r.ServesWebhooks(webhooksEnabled) {
+		return RoleController
+	}
+	return r
+}
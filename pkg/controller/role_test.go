@@ -0,0 +1,72 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestController(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Controller Setup Unit Tests")
+}
+
+var _ = Describe("Role", func() {
+	It("Parsing", func() {
+		for _, r := range []Role{RoleAll, RoleWebhook, RoleController} {
+			Expect(ParseRole(string(r))).Should(Equal(r))
+		}
+		_, err := ParseRole("")
+		Expect(err).Should(HaveOccurred())
+		_, err = ParseRole("Webhook")
+		Expect(err).Should(HaveOccurred())
+		_, err = ParseRole("scheduler")
+		Expect(err).Should(MatchError(ContainSubstring(`unknown role "scheduler"`)))
+	})
+
+	It("Components", func() {
+		Expect(RoleAll.RunsWebhooks()).Should(BeTrue())
+		Expect(RoleAll.RunsControllers()).Should(BeTrue())
+		Expect(RoleWebhook.RunsWebhooks()).Should(BeTrue())
+		Expect(RoleWebhook.RunsControllers()).Should(BeFalse())
+		Expect(RoleController.RunsWebhooks()).Should(BeFalse())
+		Expect(RoleController.RunsControllers()).Should(BeTrue())
+	})
+
+	It("Webhook and certificate management gating", func() {
+		Expect(RoleAll.ServesWebhooks(true)).Should(BeTrue())
+		Expect(RoleWebhook.ServesWebhooks(true)).Should(BeTrue())
+		Expect(RoleController.ServesWebhooks(true)).Should(BeFalse())
+		for _, r := range []Role{RoleAll, RoleWebhook, RoleController} {
+			Expect(r.ServesWebhooks(false)).Should(BeFalse())
+		}
+	})
+
+	It("Probe role", func() {
+		Expect(RoleAll.ProbeRole(true)).Should(Equal(RoleAll))
+		Expect(RoleWebhook.ProbeRole(true)).Should(Equal(RoleWebhook))
+		Expect(RoleController.ProbeRole(true)).Should(Equal(RoleController))
+		for _, r := range []Role{RoleAll, RoleWebhook, RoleController} {
+			Expect(r.ProbeRole(false)).Should(Equal(RoleController))
+			Expect(r.ProbeRole(false).RunsWebhooks()).Should(BeFalse())
+		}
+	})
+})
@@ -23,55 +23,160 @@ import (
 	"net/http"
 
 	cert "github.com/open-policy-agent/cert-controller/pkg/rotator"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
 	"github.com/project-codeflare/appwrapper/internal/controller/appwrapper"
 	"github.com/project-codeflare/appwrapper/internal/webhook"
 	"github.com/project-codeflare/appwrapper/pkg/config"
 )
 
-// SetupControllers creates and configures all components of the AppWrapper controller
-func SetupControllers(mgr ctrl.Manager, awConfig *config.AppWrapperConfig) error {
+// SetupControllers creates and configures all components of the AppWrapper controller.
+// If cmConfig specifies a NamespaceSelector, only AppWrappers in matching namespaces are reconciled.
+func SetupControllers(mgr ctrl.Manager, sharedConfig *config.SharedAppWrapperConfig, cmConfig *config.ControllerManagerConfig) error {
+	awConfig := sharedConfig.Get()
+
+	var namespaceSelector labels.Selector
+	if cmConfig.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cmConfig.NamespaceSelector)
+		if err != nil {
+			return fmt.Errorf("namespace selector: %w", err)
+		}
+		namespaceSelector = selector
+	}
+
+	var nodeEvents chan event.GenericEvent
 	if awConfig.Autopilot != nil && awConfig.Autopilot.MonitorNodes {
+		nodeEvents = make(chan event.GenericEvent, 128)
 		if err := (&appwrapper.NodeHealthMonitor{
 			Client: mgr.GetClient(),
-			Config: awConfig,
+			Config: sharedConfig,
+			Events: nodeEvents,
 		}).SetupWithManager(mgr); err != nil {
 			return fmt.Errorf("node health monitor: %w", err)
 		}
 	}
 
+	var impersonationConfig *rest.Config
+	if awConfig.ImpersonateUser {
+		impersonationConfig = mgr.GetConfig()
+	}
+
 	if err := (&appwrapper.AppWrapperReconciler{
 		Client:   mgr.GetClient(),
 		Recorder: mgr.GetEventRecorder("appwrappers"),
 		Scheme:   mgr.GetScheme(),
-		Config:   awConfig,
+		Config:   sharedConfig,
+		Events:   nodeEvents,
+
+		NamespaceSelector:   namespaceSelector,
+		ImpersonationConfig: impersonationConfig,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("appwrapper controller: %w", err)
 	}
 
+	if awConfig.Dispatcher != nil && awConfig.Dispatcher.Enabled {
+		if err := (&appwrapper.DispatcherReconciler{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Recorder:  mgr.GetEventRecorder("appwrapper-dispatcher"),
+			Scheme:    mgr.GetScheme(),
+			Config:    sharedConfig,
+
+			NamespaceSelector: namespaceSelector,
+		}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("appwrapper dispatcher: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // SetupWebhooks creates and configures the AppWrapper controller's Webhooks
-func SetupWebhooks(mgr ctrl.Manager, awConfig *config.AppWrapperConfig) error {
-	if err := webhook.SetupAppWrapperWebhook(mgr, awConfig); err != nil {
+func SetupWebhooks(mgr ctrl.Manager, sharedConfig *config.SharedAppWrapperConfig) error {
+	if err := webhook.SetupAppWrapperWebhook(mgr, sharedConfig); err != nil {
 		return fmt.Errorf("webhook: %w", err)
 	}
 	return nil
 }
 
+// SetupConfigReloader creates a controller that applies updates of the operator's ConfigMap to sharedConfig
+func SetupConfigReloader(mgr ctrl.Manager, sharedConfig *config.SharedAppWrapperConfig, configMap types.NamespacedName) error {
+	if err := (&appwrapper.ConfigReloader{
+		Client:    mgr.GetClient(),
+		Recorder:  mgr.GetEventRecorder("appwrapper-config"),
+		ConfigMap: configMap,
+		Config:    sharedConfig,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("config reloader: %w", err)
+	}
+	return nil
+}
+
+// SetupIndexers registers the field indexes used by the AppWrapper controller with the Manager's cache
 func SetupIndexers(ctx context.Context, mgr ctrl.Manager, awConfig *config.AppWrapperConfig) error {
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v1.Pod{}, appwrapper.PodAppWrapperIndex, appwrapper.IndexPodByAppWrapper); err != nil {
+		return fmt.Errorf("pod appwrapper indexer: %w", err)
+	}
+	if awConfig.Autopilot != nil && awConfig.Autopilot.MonitorNodes {
+		if err := mgr.GetFieldIndexer().IndexField(ctx, &v1.Pod{}, appwrapper.PodNodeNameIndex, appwrapper.IndexPodByNodeName); err != nil {
+			return fmt.Errorf("pod node name indexer: %w", err)
+		}
+	}
 	return nil
 }
 
-func SetupProbeEndpoints(mgr ctrl.Manager, certsReady chan struct{}) error {
+// NewCacheOptions restricts the Manager's cache of Pods to just those Pods that are labeled as belonging to an AppWrapper
+// and its cache of ConfigMaps to just the operator's configMap.
+// If cmConfig specifies Namespaces, the cache of all other namespaced objects is further restricted to those namespaces.
+func NewCacheOptions(cmConfig *config.ControllerManagerConfig, configMap types.NamespacedName) (cache.Options, error) {
+	awPod, err := labels.NewRequirement(awv1beta2.AppWrapperLabel, selection.Exists, nil)
+	if err != nil {
+		return cache.Options{}, err
+	}
+	opts := cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&v1.Pod{}: {Label: labels.NewSelector().Add(*awPod)},
+			&v1.ConfigMap{}: {Namespaces: map[string]cache.Config{
+				configMap.Namespace: {FieldSelector: fields.OneTermEqualSelector("metadata.name", configMap.Name)},
+			}},
+		},
+	}
+	if len(cmConfig.Namespaces) > 0 {
+		opts.DefaultNamespaces = make(map[string]cache.Config, len(cmConfig.Namespaces))
+		for _, ns := range cmConfig.Namespaces {
+			opts.DefaultNamespaces[ns] = cache.Config{}
+		}
+	}
+	return opts, nil
+}
+
+// SetupProbeEndpoints registers the health and readiness checks of the Manager.
+// Processes that serve webhooks are ready once their certificates are ready and the webhook server has started;
+// controller-only processes are ready as soon as they are healthy.
+func SetupProbeEndpoints(mgr ctrl.Manager, certsReady chan struct{}, role Role) error {
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return fmt.Errorf("health check: %w", err)
 	}
 
+	if !role.RunsWebhooks() {
+		if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+			return fmt.Errorf("readiness check: %w", err)
+		}
+		return nil
+	}
+
 	if err := mgr.AddReadyzCheck("readyz", func(req *http.Request) error {
 		select {
 		case <-certsReady:
@@ -89,6 +194,8 @@ func SetupProbeEndpoints(mgr ctrl.Manager, certsReady chan struct{}) error {
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=mutatingwebhookconfigurations,verbs=get;list;watch;update
 // +kubebuilder:rbac:groups="admissionregistration.k8s.io",resources=validatingwebhookconfigurations,verbs=get;list;watch;update
 
+// SetupCertManagement adds a certificate rotator for the webhooks to the Manager.
+// It should only be called by processes that serve webhooks (see Role.ServesWebhooks).
 func SetupCertManagement(mgr ctrl.Manager, config *config.CertManagementConfig, certsReady chan struct{}) error {
 	// DNSName is <service name>.<namespace>.svc
 	var dnsName = fmt.Sprintf("%s.%s.svc", config.WebhookServiceName, config.Namespace)
@@ -105,7 +212,8 @@ func SetupCertManagement(mgr ctrl.Manager, config *config.CertManagementConfig,
 			{Type: cert.Mutating, Name: config.MutatingWebhookConfigName},
 		},
 		// When the controller is running in the leader election mode,
-		// we expect webhook server will run in primary and secondary instance
+		// we expect webhook server will run in primary and secondary instance.
+		// Webhook-only processes never use leader election.
 		RequireLeaderElection: false,
 	})
 }
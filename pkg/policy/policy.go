@@ -0,0 +1,123 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy compiles and evaluates the CEL expressions of AppWrapper admission policies.
+package policy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"k8s.io/apiserver/pkg/cel/library"
+)
+
+// The targets an admission policy may be evaluated against
+const (
+	// TargetAppWrapper policies are evaluated once per AppWrapper.
+	// Variables: object (the AppWrapper) and podSets (a list of {path, replicas, template} for every PodSet).
+	TargetAppWrapper = "AppWrapper"
+	// TargetComponent policies are evaluated for every component.
+	// Variables: object (the decoded component template) and appwrapper (the AppWrapper).
+	TargetComponent = "Component"
+	// TargetPodTemplate policies are evaluated for every PodSet of every component.
+	// Variables: object (the PodTemplateSpec), replicas (the PodSet's replica count), and appwrapper (the AppWrapper).
+	TargetPodTemplate = "PodTemplate"
+)
+
+// The actions taken when an admission policy's expression does not evaluate to true
+const (
+	ActionDeny = "Deny"
+	ActionWarn = "Warn"
+)
+
+// costLimit bounds the runtime cost of evaluating a single expression
+const costLimit = 1000000
+
+// Program is a compiled admission policy expression
+type Program struct {
+	target  string
+	program cel.Program
+}
+
+var environments = sync.OnceValues(func() (map[string]*cel.Env, error) {
+	common := []cel.EnvOption{
+		cel.Variable("object", cel.DynType),
+		ext.Strings(),
+		ext.Sets(),
+		library.Lists(),
+		library.Quantity(),
+		library.Regex(),
+		library.URLs(),
+	}
+	variables := map[string][]cel.EnvOption{
+		TargetAppWrapper:  {cel.Variable("podSets", cel.ListType(cel.DynType))},
+		TargetComponent:   {cel.Variable("appwrapper", cel.DynType)},
+		TargetPodTemplate: {cel.Variable("appwrapper", cel.DynType), cel.Variable("replicas", cel.IntType)},
+	}
+	envs := make(map[string]*cel.Env, len(variables))
+	for target, opts := range variables {
+		env, err := cel.NewEnv(append(common, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		envs[target] = env
+	}
+	return envs, nil
+})
+
+// Compile compiles expression for evaluation against target; the expression must evaluate to a bool
+func Compile(target string, expression string) (*Program, error) {
+	envs, err := environments()
+	if err != nil {
+		return nil, err
+	}
+	env, ok := envs[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown target %q", target)
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %v", ast.OutputType())
+	}
+	program, err := env.Program(ast, cel.CostLimit(costLimit))
+	if err != nil {
+		return nil, err
+	}
+	return &Program{target: target, program: program}, nil
+}
+
+// Target returns the target the Program was compiled for
+func (p *Program) Target() string {
+	return p.target
+}
+
+// Eval evaluates the Program with the given variables
+func (p *Program) Eval(variables map[string]any) (bool, error) {
+	val, _, err := p.program.Eval(variables)
+	if err != nil {
+		return false, err
+	}
+	result, ok := val.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v, not a bool", val)
+	}
+	return result, nil
+}
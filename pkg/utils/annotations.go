@@ -0,0 +1,102 @@
+/*
+Copyright 2024 IBM Corporation.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+)
+
+// AppWrapperAnnotationPrefix is the common prefix of the annotations that configure an AppWrapper
+const AppWrapperAnnotationPrefix = "workload.codeflare.dev.appwrapper/"
+
+// AnnotationKind identifies the parser used for the value of an AppWrapper annotation
+type AnnotationKind int
+
+const (
+	DurationAnnotation AnnotationKind = iota
+	LimitAnnotation
+	ExitCodesAnnotation
+	StringAnnotation
+	BoolAnnotation
+)
+
+// AppWrapperAnnotations maps every annotation understood by the AppWrapper controller to the kind of its value
+var AppWrapperAnnotations = map[string]AnnotationKind{
+	awv1beta2.AdmissionGracePeriodDurationAnnotation:  DurationAnnotation,
+	awv1beta2.WarmupGracePeriodDurationAnnotation:     DurationAnnotation,
+	awv1beta2.FailureGracePeriodDurationAnnotation:    DurationAnnotation,
+	awv1beta2.RetryPausePeriodDurationAnnotation:      DurationAnnotation,
+	awv1beta2.RetryLimitAnnotation:                    LimitAnnotation,
+	awv1beta2.ForcefulDeletionGracePeriodAnnotation:   DurationAnnotation,
+	awv1beta2.DeletionOnFailureGracePeriodAnnotation:  DurationAnnotation,
+	awv1beta2.SuspensionGracePeriodDurationAnnotation: DurationAnnotation,
+	awv1beta2.ActiveDeadlineDurationAnnotation:        DurationAnnotation,
+	awv1beta2.SuspendOnActiveDeadlineAnnotation:       BoolAnnotation,
+	awv1beta2.SuccessTTLAnnotation:                    DurationAnnotation,
+	awv1beta2.TerminalExitCodesAnnotation:             ExitCodesAnnotation,
+	awv1beta2.RetryableExitCodesAnnotation:            ExitCodesAnnotation,
+	awv1beta2.WorkerClusterAnnotation:                 StringAnnotation,
+	awv1beta2.MergeIdenticalPodSetsAnnotation:         BoolAnnotation,
+	awv1beta2.RestartRequestAnnotation:                StringAnnotation,
+	awv1beta2.RestartCountsAsRetryAnnotation:          BoolAnnotation,
+	awv1beta2.HoldAnnotation:                          BoolAnnotation,
+}
+
+// ParseDurationAnnotation parses the value of a duration-valued annotation
+func ParseDurationAnnotation(value string) (time.Duration, error) {
+	return time.ParseDuration(value)
+}
+
+// ParseLimitAnnotation parses the value of a limit-valued annotation, which must be a non-negative int32
+func ParseLimitAnnotation(value string) (int32, error) {
+	limit, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("limit %v must not be negative", limit)
+	}
+	return int32(limit), nil
+}
+
+// ParseBoolAnnotation parses the value of a bool-valued annotation
+func ParseBoolAnnotation(value string) (bool, error) {
+	return strconv.ParseBool(value)
+}
+
+// ParseExitCodesAnnotation parses a comma-separated list of exit codes.
+// It returns the exit codes that could be parsed and an error describing those that could not.
+func ParseExitCodesAnnotation(value string) ([]int, error) {
+	ans := []int{}
+	malformed := []string{}
+	for _, str := range strings.Split(value, ",") {
+		if exitCode, err := strconv.Atoi(str); err == nil {
+			ans = append(ans, exitCode)
+		} else {
+			malformed = append(malformed, str)
+		}
+	}
+	if len(malformed) > 0 {
+		return ans, fmt.Errorf("malformed exit codes %q", malformed)
+	}
+	return ans, nil
+}
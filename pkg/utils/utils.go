@@ -18,7 +18,9 @@ package utils
 
 import (
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -26,6 +28,7 @@ import (
 	kftraining "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -158,7 +161,7 @@ func copyContainers(src []v1.Container) []v1.Container {
 			dst[i].TerminationMessagePolicy = src[i].TerminationMessagePolicy
 		}
 		if src[i].ImagePullPolicy == "" {
-			if getImageTag(src[i].Image) == "latest" {
+			if GetImageTag(src[i].Image) == "latest" {
 				dst[i].ImagePullPolicy = v1.PullAlways
 			} else {
 				dst[i].ImagePullPolicy = v1.PullIfNotPresent
@@ -181,9 +184,9 @@ func defaultResourceList(rl v1.ResourceList) v1.ResourceList {
 	return rl
 }
 
-// getImageTag parses a docker image string and returns the tag.
+// GetImageTag parses a docker image string and returns the tag.
 // If both tag and digest are empty,"latest" will be returned.
-func getImageTag(image string) string {
+func GetImageTag(image string) string {
 	named, err := dockerref.ParseNormalizedNamed(image)
 	if err != nil {
 		return ""
@@ -325,27 +328,106 @@ func EnsureComponentStatusInitialized(aw *awv1beta2.AppWrapper) error {
 	// Construct definitive PodSets from the Spec + InferPodSets and cache in the Status (to avoid clashing with user updates to the Spec via apply)
 	compStatus := make([]awv1beta2.AppWrapperComponentStatus, len(aw.Spec.Components))
 	for idx := range aw.Spec.Components {
-		if len(aw.Spec.Components[idx].DeclaredPodSets) > 0 {
-			compStatus[idx].PodSets = aw.Spec.Components[idx].DeclaredPodSets
-		} else {
-			obj := &unstructured.Unstructured{}
-			if _, _, err := unstructured.UnstructuredJSONScheme.Decode(aw.Spec.Components[idx].Template.Raw, nil, obj); err != nil {
-				// Transient error; Template.Raw was validated by our AdmissionController
-				return err
-			}
-			podSets, err := InferPodSets(obj)
-			if err != nil {
-				// Transient error; InferPodSets was validated by our AdmissionController
-				return err
-			}
-			compStatus[idx].PodSets = podSets
+		_, podSets, err := componentPodSets(&aw.Spec.Components[idx])
+		if err != nil {
+			// Transient error; Template.Raw and InferPodSets were validated by our AdmissionController
+			return err
 		}
+		compStatus[idx].PodSets = podSets
 	}
 	aw.Status.ComponentStatus = compStatus
 	return nil
 }
 
+// ComponentStatusOutdated returns true if aw.Status.ComponentStatus no longer reflects aw.Spec.Components,
+// which happens when the Spec of a Suspended AppWrapper is edited. It compares the PodSets of every component
+// and, for components that were previously deployed, their Kind, APIVersion and Name.
+func ComponentStatusOutdated(aw *awv1beta2.AppWrapper) (bool, error) {
+	if len(aw.Status.ComponentStatus) != len(aw.Spec.Components) {
+		return true, nil
+	}
+	for idx := range aw.Spec.Components {
+		obj, podSets, err := componentPodSets(&aw.Spec.Components[idx])
+		if err != nil {
+			return false, err
+		}
+		cs := aw.Status.ComponentStatus[idx]
+		if !equality.Semantic.DeepEqual(cs.PodSets, podSets) {
+			return true, nil
+		}
+		if cs.Kind != "" && (cs.Kind != obj.GetKind() || cs.APIVersion != obj.GetAPIVersion()) {
+			return true, nil
+		}
+		if cs.Name != "" && obj.GetName() != "" && cs.Name != obj.GetName() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// componentPodSets decodes the Template of component and returns it together with its definitive PodSets
+func componentPodSets(component *awv1beta2.AppWrapperComponent) (*unstructured.Unstructured, []awv1beta2.AppWrapperPodSet, error) {
+	obj := &unstructured.Unstructured{}
+	if _, _, err := unstructured.UnstructuredJSONScheme.Decode(component.Template.Raw, nil, obj); err != nil {
+		return nil, nil, err
+	}
+	if len(component.DeclaredPodSets) > 0 {
+		return obj, component.DeclaredPodSets, nil
+	}
+	podSets, err := InferPodSets(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, podSets, nil
+}
+
+// GetComponentPodSpecs returns the PodTemplateSpec of every PodSet of aw's components together with its PodSet.
+// If aw requests it via the MergeIdenticalPodSetsAnnotation, PodSets with identical templates are merged.
 func GetComponentPodSpecs(aw *awv1beta2.AppWrapper) ([]*v1.PodTemplateSpec, []awv1beta2.AppWrapperPodSet, error) {
+	templates, podSets, err := getComponentPodSpecs(aw)
+	if err != nil || !MergeIdenticalPodSets(aw) {
+		return templates, podSets, err
+	}
+	mergedTemplates, mergedPodSets, _ := mergePodSets(templates, podSets)
+	return mergedTemplates, mergedPodSets, nil
+}
+
+// MergeIdenticalPodSets returns true if aw requests that PodSets with identical templates be merged
+func MergeIdenticalPodSets(aw *awv1beta2.AppWrapper) bool {
+	merge, err := ParseBoolAnnotation(aw.Annotations[awv1beta2.MergeIdenticalPodSetsAnnotation])
+	return err == nil && merge
+}
+
+// mergePodSets merges PodSets whose templates and annotations are semantically equal by summing their replicas.
+// It returns the merged templates and PodSets and, for every input PodSet, the index of the merged PodSet that contains it.
+func mergePodSets(templates []*v1.PodTemplateSpec, podSets []awv1beta2.AppWrapperPodSet) ([]*v1.PodTemplateSpec, []awv1beta2.AppWrapperPodSet, []int) {
+	mergedTemplates := []*v1.PodTemplateSpec{}
+	mergedPodSets := []awv1beta2.AppWrapperPodSet{}
+	groups := make([]int, len(templates))
+	for i, template := range templates {
+		groups[i] = slices.IndexFunc(mergedTemplates, func(t *v1.PodTemplateSpec) bool {
+			return equality.Semantic.DeepEqual(t, template)
+		})
+		if groups[i] >= 0 && !maps.Equal(mergedPodSets[groups[i]].Annotations, podSets[i].Annotations) {
+			groups[i] = -1
+		}
+		if groups[i] < 0 {
+			groups[i] = len(mergedTemplates)
+			mergedTemplates = append(mergedTemplates, template)
+			mergedPodSets = append(mergedPodSets, awv1beta2.AppWrapperPodSet{
+				Path:        podSets[i].Path,
+				Replicas:    ptr.To(Replicas(podSets[i])),
+				Annotations: podSets[i].Annotations,
+			})
+		} else {
+			merged := &mergedPodSets[groups[i]]
+			merged.Replicas = ptr.To(*merged.Replicas + Replicas(podSets[i]))
+		}
+	}
+	return mergedTemplates, mergedPodSets, groups
+}
+
+func getComponentPodSpecs(aw *awv1beta2.AppWrapper) ([]*v1.PodTemplateSpec, []awv1beta2.AppWrapperPodSet, error) {
 	templates := []*v1.PodTemplateSpec{}
 	podSets := []awv1beta2.AppWrapperPodSet{}
 	if err := EnsureComponentStatusInitialized(aw); err != nil {
@@ -369,11 +451,53 @@ func GetComponentPodSpecs(aw *awv1beta2.AppWrapper) ([]*v1.PodTemplateSpec, []aw
 	return templates, podSets, nil
 }
 
-// SetPodSetInfos propagates podSetsInfo into the PodSetInfos of aw.Spec.Components
+// GetComponentPodTemplates returns the complete content of every PodTemplateSpec of aw's components together with its PodSet.
+// Unlike GetComponentPodSpecs, the templates are not reduced to the subset of fields that is relevant to Kueue.
+func GetComponentPodTemplates(aw *awv1beta2.AppWrapper) ([]map[string]interface{}, []awv1beta2.AppWrapperPodSet, error) {
+	templates := []map[string]interface{}{}
+	podSets := []awv1beta2.AppWrapperPodSet{}
+	if err := EnsureComponentStatusInitialized(aw); err != nil {
+		return nil, nil, err
+	}
+	for idx := range aw.Status.ComponentStatus {
+		if len(aw.Status.ComponentStatus[idx].PodSets) > 0 {
+			obj := &unstructured.Unstructured{}
+			if _, _, err := unstructured.UnstructuredJSONScheme.Decode(aw.Spec.Components[idx].Template.Raw, nil, obj); err != nil {
+				return nil, nil, err
+			}
+			for _, podSet := range aw.Status.ComponentStatus[idx].PodSets {
+				if template, err := GetRawTemplate(obj.UnstructuredContent(), podSet.Path); err == nil {
+					templates = append(templates, template)
+					podSets = append(podSets, podSet)
+				}
+			}
+		}
+	}
+	return templates, podSets, nil
+}
+
+// SetPodSetInfos propagates podSetsInfo into the PodSetInfos of aw.Spec.Components.
+// If aw's identical PodSets are merged by GetComponentPodSpecs, podSetsInfo must contain one entry
+// per merged PodSet; that entry is propagated to every PodSet that was merged into it.
 func SetPodSetInfos(aw *awv1beta2.AppWrapper, podSetsInfo []awv1beta2.AppWrapperPodSetInfo) error {
 	if err := EnsureComponentStatusInitialized(aw); err != nil {
 		return err
 	}
+	if MergeIdenticalPodSets(aw) {
+		templates, podSets, err := getComponentPodSpecs(aw)
+		if err != nil {
+			return err
+		}
+		_, merged, groups := mergePodSets(templates, podSets)
+		if len(merged) != len(podSetsInfo) {
+			return fmt.Errorf("expecting %d podsets, got %d", len(merged), len(podSetsInfo))
+		}
+		expanded := make([]awv1beta2.AppWrapperPodSetInfo, len(groups))
+		for i, group := range groups {
+			expanded[i] = podSetsInfo[group]
+		}
+		podSetsInfo = expanded
+	}
 	podSetsInfoIndex := 0
 	for idx := range aw.Spec.Components {
 		if len(aw.Spec.Components[idx].PodSetInfos) != len(aw.Status.ComponentStatus[idx].PodSets) {